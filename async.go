@@ -0,0 +1,190 @@
+package log
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	ErrFlushTimeout = errors.New("log: flush timeout, some entries are still in the queue")
+	ErrClosed       = errors.New("log: writer already closed")
+)
+
+type (
+	// AsyncConfig write log output from a background goroutine, so slow output does not block the caller
+	AsyncConfig struct {
+		QueueSize    int           // Maximum entries waiting to be written. Default 1024
+		DropWhenFull bool          // Drop new entries when the queue is full instead of blocking the caller
+		FlushTimeout time.Duration // Maximum time Flush and Close wait for the queue to drain. Default 5 second
+	}
+
+	// asyncWriter is a bounded in-memory queue drained by a single background writer
+	asyncWriter struct {
+		out      io.Writer
+		config   AsyncConfig
+		queue    chan asyncEntry
+		done     chan struct{}
+		stopping chan struct{} // Closed by Close, unblock writers waiting for a full queue
+		stopOnce sync.Once
+		mu       sync.RWMutex // Guard queue from being closed while writing
+		closed   bool
+		dropped  atomic.Uint64
+	}
+
+	// asyncEntry is either log data or a flush marker
+	asyncEntry struct {
+		data    []byte
+		flushed chan struct{}
+	}
+)
+
+func newAsyncWriter(out io.Writer, config AsyncConfig) *asyncWriter {
+	if config.QueueSize <= 0 {
+		config.QueueSize = 1024
+	}
+	if config.FlushTimeout <= 0 {
+		config.FlushTimeout = 5 * time.Second
+	}
+
+	w := &asyncWriter{
+		out:      out,
+		config:   config,
+		queue:    make(chan asyncEntry, config.QueueSize),
+		done:     make(chan struct{}),
+		stopping: make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+func (w *asyncWriter) run() {
+	defer close(w.done)
+	for entry := range w.queue {
+		if entry.flushed != nil {
+			close(entry.flushed)
+			continue
+		}
+		w.out.Write(entry.data)
+	}
+}
+
+// Write copy p into the queue, the caller buffer can be reused after return
+func (w *asyncWriter) Write(p []byte) (int, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed {
+		return 0, ErrClosed
+	}
+
+	entry := asyncEntry{data: append([]byte(nil), p...)}
+	if !w.config.DropWhenFull {
+		select {
+		case w.queue <- entry:
+			return len(p), nil
+		case <-w.stopping:
+			w.dropped.Add(1)
+			return 0, ErrClosed
+		}
+	}
+
+	select {
+	case w.queue <- entry:
+	default:
+		w.dropped.Add(1)
+	}
+	return len(p), nil
+}
+
+// Flush wait until every entry written before this call reach the output
+func (w *asyncWriter) Flush() error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed {
+		return nil
+	}
+
+	timeout := time.NewTimer(w.config.FlushTimeout)
+	defer timeout.Stop()
+
+	flushed := make(chan struct{})
+	select {
+	case w.queue <- asyncEntry{flushed: flushed}:
+	case <-timeout.C:
+		return ErrFlushTimeout
+	}
+
+	select {
+	case <-flushed:
+		return nil
+	case <-timeout.C:
+		return ErrFlushTimeout
+	}
+}
+
+// Close drain the queue and stop the background writer.
+// Writers still blocked by a full queue after the flush return ErrClosed.
+func (w *asyncWriter) Close() error {
+	err := w.Flush()
+
+	w.stopOnce.Do(func() { close(w.stopping) })
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	close(w.queue)
+	w.mu.Unlock()
+
+	select {
+	case <-w.done:
+	case <-time.After(w.config.FlushTimeout):
+		return ErrFlushTimeout
+	}
+	return err
+}
+
+// Flush wait until queued entries are written, no-op when async output is disabled
+func (l *Logger) Flush() error {
+	var errs []error
+	for _, async := range l.async {
+		errs = append(errs, async.Flush())
+	}
+	return errors.Join(errs...)
+}
+
+// Close drain the queue and close the file output. Logging after Close is discarded.
+func (l *Logger) Close() error {
+	var errs []error
+	for _, async := range l.async {
+		errs = append(errs, async.Close())
+	}
+	for _, closer := range l.closers {
+		errs = append(errs, closer.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// Dropped return the number of entries dropped because the async queue was full
+func (l *Logger) Dropped() uint64 {
+	var dropped uint64
+	for _, async := range l.async {
+		dropped += async.dropped.Load()
+	}
+	return dropped
+}
+
+// Flush wait until queued entries of the default logger are written
+func Flush() error {
+	return Default().Flush()
+}
+
+// Close drain and close the default logger output
+func Close() error {
+	return Default().Close()
+}
@@ -0,0 +1,195 @@
+package log
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingWriter block every write until release is closed
+type blockingWriter struct {
+	release chan struct{}
+	mu      sync.Mutex
+	writes  int
+}
+
+func newBlockingWriter() *blockingWriter {
+	return &blockingWriter{release: make(chan struct{})}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	<-w.release
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writes++
+	return len(p), nil
+}
+
+func (w *blockingWriter) count() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.writes
+}
+
+func TestAsyncWriterDropWhenFull(t *testing.T) {
+	out := newBlockingWriter()
+	w := newAsyncWriter(out, AsyncConfig{QueueSize: 2, DropWhenFull: true})
+
+	const lines = 10
+	start := time.Now()
+	for i := 0; i < lines; i++ {
+		if n, err := w.Write([]byte("line\n")); err != nil || n != 5 {
+			t.Fatalf("expected write accepted, got %d %v", n, err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected full queue not to block the caller, took %v", elapsed)
+	}
+	if dropped := w.dropped.Load(); dropped < lines-3 {
+		t.Fatalf("expected at least %d dropped lines with queue size 2, got %d", lines-3, dropped)
+	}
+
+	close(out.release)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if written := out.count() + int(w.dropped.Load()); written != lines {
+		t.Fatalf("expected every line written or dropped, got %d of %d", written, lines)
+	}
+}
+
+func TestAsyncWriterFlushTimeout(t *testing.T) {
+	out := newBlockingWriter()
+	w := newAsyncWriter(out, AsyncConfig{QueueSize: 1, FlushTimeout: 50 * time.Millisecond})
+	w.Write([]byte("blocked\n"))
+
+	start := time.Now()
+	if err := w.Flush(); !errors.Is(err, ErrFlushTimeout) {
+		t.Fatalf("expected ErrFlushTimeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected Flush to return after FlushTimeout, took %v", elapsed)
+	}
+
+	close(out.release)
+	if err := w.Flush(); err != nil {
+		t.Fatalf("expected Flush to succeed once the output is released, got %v", err)
+	}
+	if out.count() != 1 {
+		t.Fatalf("expected 1 write, got %d", out.count())
+	}
+	w.Close()
+}
+
+func TestAsyncWriterClose(t *testing.T) {
+	out := newBlockingWriter()
+	close(out.release)
+	w := newAsyncWriter(out, AsyncConfig{})
+
+	for i := 0; i < 100; i++ {
+		w.Write([]byte("line\n"))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if out.count() != 100 {
+		t.Fatalf("expected Close to drain the queue, got %d writes", out.count())
+	}
+
+	// Background goroutine is stopped
+	select {
+	case <-w.done:
+	default:
+		t.Fatal("expected background writer stopped after Close")
+	}
+
+	if _, err := w.Write([]byte("after close\n")); !errors.Is(err, ErrClosed) {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("expected Flush after Close to be a no-op, got %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("expected second Close to be a no-op, got %v", err)
+	}
+}
+
+func TestAsyncWriterCloseWithBlockedOutput(t *testing.T) {
+	out := newBlockingWriter()
+	w := newAsyncWriter(out, AsyncConfig{QueueSize: 4, FlushTimeout: 50 * time.Millisecond})
+	w.Write([]byte("blocked\n"))
+
+	if err := w.Close(); !errors.Is(err, ErrFlushTimeout) {
+		t.Fatalf("expected ErrFlushTimeout while the output is blocked, got %v", err)
+	}
+
+	// Background goroutine exit once the output return
+	close(out.release)
+	select {
+	case <-w.done:
+	case <-time.After(time.Second):
+		t.Fatal("expected background writer to stop after the output is released")
+	}
+}
+
+func TestLoggerAsyncDropped(t *testing.T) {
+	out := newBlockingWriter()
+	logger := newTestLogger(t, out, Config{Async: &AsyncConfig{QueueSize: 1, DropWhenFull: true}})
+
+	const lines = 20
+	for i := 0; i < lines; i++ {
+		logger.Info("line")
+	}
+	if logger.Dropped() == 0 {
+		t.Fatal("expected dropped lines counted by the logger")
+	}
+
+	close(out.release)
+	if err := logger.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if written := out.count() + int(logger.Dropped()); written != lines {
+		t.Fatalf("expected every line written or dropped, got %d of %d", written, lines)
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAsyncWriterCloseUnblockWriters(t *testing.T) {
+	out := newBlockingWriter()
+	defer close(out.release)
+	w := newAsyncWriter(out, AsyncConfig{QueueSize: 1, FlushTimeout: 50 * time.Millisecond})
+
+	// First line block the output, second fill the queue, the rest wait for space
+	errs := make(chan error, 4)
+	for i := 0; i < cap(errs); i++ {
+		go func() {
+			_, err := w.Write([]byte("line\n"))
+			errs <- err
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	closed := make(chan error, 1)
+	go func() { closed <- w.Close() }()
+	select {
+	case err := <-closed:
+		if !errors.Is(err, ErrFlushTimeout) {
+			t.Fatalf("expected ErrFlushTimeout, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected Close not to hang on writers blocked by a full queue")
+	}
+
+	var rejected int
+	for i := 0; i < cap(errs); i++ {
+		if err := <-errs; errors.Is(err, ErrClosed) {
+			rejected++
+		}
+	}
+	if rejected == 0 || uint64(rejected) != w.dropped.Load() {
+		t.Fatalf("expected blocked writers rejected and counted as dropped, rejected %d dropped %d", rejected, w.dropped.Load())
+	}
+}
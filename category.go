@@ -0,0 +1,49 @@
+package log
+
+import "log/slog"
+
+// TRACE and REQUEST rank above FATAL, so Config.Level can not filter them without filtering errors too.
+// They are switched on and off independently from the level of application logs.
+
+// SetTraceEnabled enable or disable TRACE entries written by trace.Save at runtime
+func (l *Logger) SetTraceEnabled(enable bool) {
+	l.traceDisabled.Store(!enable)
+}
+
+// SetRequestEnabled enable or disable REQUEST entries written by request.Save at runtime.
+// Sub-logs of a disabled request are discarded, use DisableSubLogs to write them as global log.
+func (l *Logger) SetRequestEnabled(enable bool) {
+	l.requestDisabled.Store(!enable)
+}
+
+// TraceEnabled report whether TRACE entries are written
+func (l *Logger) TraceEnabled() bool {
+	return !l.traceDisabled.Load()
+}
+
+// RequestEnabled report whether REQUEST entries are written
+func (l *Logger) RequestEnabled() bool {
+	return !l.requestDisabled.Load()
+}
+
+// categoryEnabled apply the TRACE and REQUEST switches, application levels are always enabled here
+func (l *Logger) categoryEnabled(level slog.Level) bool {
+	switch level {
+	case LevelTrace:
+		return l.TraceEnabled()
+	case LevelRequest:
+		return l.RequestEnabled()
+	default:
+		return true
+	}
+}
+
+// SetTraceEnabled enable or disable TRACE entries of the default logger
+func SetTraceEnabled(enable bool) {
+	Default().SetTraceEnabled(enable)
+}
+
+// SetRequestEnabled enable or disable REQUEST entries of the default logger
+func SetRequestEnabled(enable bool) {
+	Default().SetRequestEnabled(enable)
+}
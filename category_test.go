@@ -0,0 +1,68 @@
+package log
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestCategorySwitches(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        Config
+		disable       func(logger *Logger)
+		expectRequest bool
+		expectTrace   bool
+	}{
+		{"all enabled", Config{}, nil, true, true},
+		{"trace disabled by config", Config{DisableTraceLog: true}, nil, true, false},
+		{"request disabled by config", Config{DisableRequestLog: true}, nil, false, true},
+		{"trace disabled at runtime", Config{}, func(logger *Logger) { logger.SetTraceEnabled(false) }, true, false},
+		{"request disabled at runtime", Config{}, func(logger *Logger) { logger.SetRequestEnabled(false) }, false, true},
+		{"enabled again at runtime", Config{DisableTraceLog: true, DisableRequestLog: true}, func(logger *Logger) {
+			logger.SetTraceEnabled(true)
+			logger.SetRequestEnabled(true)
+		}, true, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			logger, recorder := newRecordingLogger(t, test.config)
+			if test.disable != nil {
+				test.disable(logger)
+			}
+
+			req := logger.NewRequest()
+			trace := NewTrace(http.MethodGet, "https://api.local/users", nil, nil, false)
+			trace.Save(req.SaveToContext(context.Background()), &http.Response{StatusCode: http.StatusOK, Header: http.Header{}})
+			req.Error("application log")
+			req.Save()
+			logger.Error("global error") // Application levels are not affected by the switches
+
+			requests := recorder.requests(t, logger)
+			recorder.mu.Lock()
+			defer recorder.mu.Unlock()
+
+			var traces, errors int
+			for _, entry := range recorder.entries {
+				switch {
+				case entry.Trace != nil:
+					traces++
+				case entry.Level == LevelError:
+					errors++
+				}
+			}
+			if (len(requests) == 1) != test.expectRequest {
+				t.Errorf("expected REQUEST written %v, got %d entries", test.expectRequest, len(requests))
+			}
+			if (traces == 1) != test.expectTrace {
+				t.Errorf("expected TRACE written %v, got %d entries", test.expectTrace, traces)
+			}
+			if errors != 1 {
+				t.Errorf("expected the ERROR global log always written, got %d", errors)
+			}
+			if logger.RequestEnabled() != test.expectRequest || logger.TraceEnabled() != test.expectTrace {
+				t.Errorf("expected switches request %v trace %v, got %v %v", test.expectRequest, test.expectTrace, logger.RequestEnabled(), logger.TraceEnabled())
+			}
+		})
+	}
+}
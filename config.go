@@ -0,0 +1,663 @@
+package log
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+type (
+	// fileConfig is the serialized form of Config used by LoadConfig and ConfigFromEnv.
+	// Pointer field is nil when the value is not set, so the default value is kept.
+	fileConfig struct {
+		LogToTerminal     *bool                `json:"log_to_terminal" yaml:"log_to_terminal"`
+		LogToFile         *bool                `json:"log_to_file" yaml:"log_to_file"`
+		Location          *string              `json:"location" yaml:"location"`
+		FileLogName       *string              `json:"file_log_name" yaml:"file_log_name"`
+		ServiceName       *string              `json:"service_name" yaml:"service_name"`
+		DirMode           *string              `json:"dir_mode" yaml:"dir_mode"`
+		FileMode          *string              `json:"file_mode" yaml:"file_mode"`
+		FileFormat        *string              `json:"file_format" yaml:"file_format"`
+		MaxAge            *int                 `json:"max_age" yaml:"max_age"`
+		RotationFile      *int                 `json:"rotation_file" yaml:"rotation_file"`
+		MaxSize           *int                 `json:"max_size" yaml:"max_size"`
+		MaxBackups        *int                 `json:"max_backups" yaml:"max_backups"`
+		MaxTotalSize      *int                 `json:"max_total_size" yaml:"max_total_size"`
+		Compress          *string              `json:"compress" yaml:"compress"`
+		RotationMode      *string              `json:"rotation_mode" yaml:"rotation_mode"`
+		Level             *string              `json:"level" yaml:"level"`
+		SubLogLevel       *string              `json:"sub_log_level" yaml:"sub_log_level"`
+		Outputs           []string             `json:"outputs" yaml:"outputs"`
+		HideSensitiveData *bool                `json:"hide_sensitive_data" yaml:"hide_sensitive_data"`
+		DisableSubLogs    *bool                `json:"disable_sub_logs" yaml:"disable_sub_logs"`
+		LevelRules        map[string]string    `json:"level_rules" yaml:"level_rules"`
+		Format            *string              `json:"format" yaml:"format"`
+		MirrorSubLogs     *bool                `json:"mirror_sub_logs" yaml:"mirror_sub_logs"`
+		DisableTraceLog   *bool                `json:"disable_trace_log" yaml:"disable_trace_log"`
+		DisableRequestLog *bool                `json:"disable_request_log" yaml:"disable_request_log"`
+		Sampling          *fileSampling        `json:"sampling" yaml:"sampling"`
+		Async             *fileAsync           `json:"async" yaml:"async"`
+		Sinks             []fileSink           `json:"sinks" yaml:"sinks"`
+		SubLogLimit       *fileSubLogLimit     `json:"sub_log_limit" yaml:"sub_log_limit"`
+		SubLogRetention   *fileSubLogRetention `json:"sub_log_retention" yaml:"sub_log_retention"`
+	}
+
+	fileSubLogRetention struct {
+		MinStatus       *int              `json:"min_status" yaml:"min_status"`
+		SlowThreshold   *string           `json:"slow_threshold" yaml:"slow_threshold"`
+		RouteThresholds map[string]string `json:"route_thresholds" yaml:"route_thresholds"`
+	}
+
+	fileSubLogLimit struct {
+		Head           *int `json:"head" yaml:"head"`
+		Tail           *int `json:"tail" yaml:"tail"`
+		MaxBytes       *int `json:"max_bytes" yaml:"max_bytes"`
+		MaxMessageSize *int `json:"max_message_size" yaml:"max_message_size"`
+	}
+
+	fileSampling struct {
+		Initial     *int               `json:"initial" yaml:"initial"`
+		Thereafter  *int               `json:"thereafter" yaml:"thereafter"`
+		Tick        *string            `json:"tick" yaml:"tick"`
+		RequestRate *float64           `json:"request_rate" yaml:"request_rate"`
+		RouteRates  map[string]float64 `json:"route_rates" yaml:"route_rates"`
+	}
+
+	fileSink struct {
+		Output   string   `json:"output" yaml:"output"`
+		Format   *string  `json:"format" yaml:"format"`
+		MinLevel *string  `json:"min_level" yaml:"min_level"`
+		MaxLevel *string  `json:"max_level" yaml:"max_level"`
+		Levels   []string `json:"levels" yaml:"levels"`
+	}
+
+	fileAsync struct {
+		QueueSize    *int    `json:"queue_size" yaml:"queue_size"`
+		DropWhenFull *bool   `json:"drop_when_full" yaml:"drop_when_full"`
+		FlushTimeout *string `json:"flush_timeout" yaml:"flush_timeout"`
+	}
+)
+
+// LoadConfig read config from YAML (.yaml, .yml) or JSON (.json) file.
+// Field which is not set in the file keep the value from DefaultConfig.
+func LoadConfig(path string) (Config, error) {
+	raw, err := readConfigFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+	return raw.toConfig()
+}
+
+func readConfigFile(path string) (fileConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fileConfig{}, fmt.Errorf("failed read log config file, %w", err)
+	}
+
+	var raw fileConfig
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(strings.NewReader(string(data)))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&raw); err != nil && !errors.Is(err, io.EOF) {
+			return fileConfig{}, fmt.Errorf("failed parse log config file %s, %w", path, err)
+		}
+	case ".json":
+		decoder := json.NewDecoder(strings.NewReader(string(data)))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&raw); err != nil {
+			return fileConfig{}, fmt.Errorf("failed parse log config file %s, %w", path, err)
+		}
+	default:
+		return fileConfig{}, fmt.Errorf("unsupported log config file extension %q, use .yaml, .yml or .json", filepath.Ext(path))
+	}
+
+	return raw, nil
+}
+
+// ConfigFromEnv read config from environment variables with the given prefix, example prefix "APP" read
+// APP_LEVEL, APP_FORMAT, APP_OUTPUTS, APP_LEVEL_RULES, APP_SAMPLING_INITIAL, APP_ASYNC_QUEUE_SIZE and so on.
+// Variable which is not set keep the value from DefaultConfig.
+func ConfigFromEnv(prefix string) (Config, error) {
+	if prefix != "" && !strings.HasSuffix(prefix, "_") {
+		prefix += "_"
+	}
+
+	var (
+		raw  fileConfig
+		errs []error
+		env  = envReader{prefix: prefix, errs: &errs}
+	)
+
+	raw.LogToTerminal = env.bool("LOG_TO_TERMINAL")
+	raw.LogToFile = env.bool("LOG_TO_FILE")
+	raw.Location = env.string("LOCATION")
+	raw.FileLogName = env.string("FILE_LOG_NAME")
+	raw.ServiceName = env.string("SERVICE_NAME")
+	raw.DirMode = env.string("DIR_MODE")
+	raw.FileMode = env.string("FILE_MODE")
+	raw.FileFormat = env.string("FILE_FORMAT")
+	raw.MaxAge = env.int("MAX_AGE")
+	raw.RotationFile = env.int("ROTATION_FILE")
+	raw.MaxSize = env.int("MAX_SIZE")
+	raw.MaxBackups = env.int("MAX_BACKUPS")
+	raw.MaxTotalSize = env.int("MAX_TOTAL_SIZE")
+	raw.Compress = env.string("COMPRESS")
+	raw.RotationMode = env.string("ROTATION_MODE")
+	raw.Level = env.string("LEVEL")
+	raw.SubLogLevel = env.string("SUB_LOG_LEVEL")
+	raw.Outputs = env.list("OUTPUTS")
+	raw.HideSensitiveData = env.bool("HIDE_SENSITIVE_DATA")
+	raw.DisableSubLogs = env.bool("DISABLE_SUB_LOGS")
+	raw.LevelRules = env.pairs("LEVEL_RULES")
+	raw.Format = env.string("FORMAT")
+	raw.MirrorSubLogs = env.bool("MIRROR_SUB_LOGS")
+	raw.DisableTraceLog = env.bool("DISABLE_TRACE_LOG")
+	raw.DisableRequestLog = env.bool("DISABLE_REQUEST_LOG")
+
+	sampling := fileSampling{
+		Initial:     env.int("SAMPLING_INITIAL"),
+		Thereafter:  env.int("SAMPLING_THEREAFTER"),
+		Tick:        env.string("SAMPLING_TICK"),
+		RequestRate: env.float("SAMPLING_REQUEST_RATE"),
+	}
+	for route, rate := range env.pairs("SAMPLING_ROUTE_RATES") {
+		value, err := strconv.ParseFloat(rate, 64)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%sSAMPLING_ROUTE_RATES: invalid rate %q for route %q", prefix, rate, route))
+			continue
+		}
+		if sampling.RouteRates == nil {
+			sampling.RouteRates = make(map[string]float64)
+		}
+		sampling.RouteRates[route] = value
+	}
+	if sampling.Initial != nil || sampling.Thereafter != nil || sampling.Tick != nil || sampling.RequestRate != nil || sampling.RouteRates != nil {
+		raw.Sampling = &sampling
+	}
+
+	async := fileAsync{
+		QueueSize:    env.int("ASYNC_QUEUE_SIZE"),
+		DropWhenFull: env.bool("ASYNC_DROP_WHEN_FULL"),
+		FlushTimeout: env.string("ASYNC_FLUSH_TIMEOUT"),
+	}
+	enableAsync := env.bool("ASYNC")
+	if (enableAsync != nil && *enableAsync) || (enableAsync == nil && async != fileAsync{}) {
+		raw.Async = &async
+	}
+
+	subLogLimit := fileSubLogLimit{
+		Head:           env.int("SUB_LOG_HEAD"),
+		Tail:           env.int("SUB_LOG_TAIL"),
+		MaxBytes:       env.int("SUB_LOG_MAX_BYTES"),
+		MaxMessageSize: env.int("SUB_LOG_MAX_MESSAGE_SIZE"),
+	}
+	if subLogLimit != (fileSubLogLimit{}) {
+		raw.SubLogLimit = &subLogLimit
+	}
+
+	retention := fileSubLogRetention{
+		MinStatus:       env.int("SUB_LOG_RETENTION_MIN_STATUS"),
+		SlowThreshold:   env.string("SUB_LOG_RETENTION_SLOW_THRESHOLD"),
+		RouteThresholds: env.pairs("SUB_LOG_RETENTION_ROUTE_THRESHOLDS"),
+	}
+	enableRetention := env.bool("SUB_LOG_RETENTION")
+	if (enableRetention != nil && *enableRetention) || (enableRetention == nil && (retention.MinStatus != nil || retention.SlowThreshold != nil || retention.RouteThresholds != nil)) {
+		raw.SubLogRetention = &retention
+	}
+
+	if len(errs) > 0 {
+		return Config{}, fmt.Errorf("invalid log config from environment, %w", errors.Join(errs...))
+	}
+	return raw.toConfig()
+}
+
+// toConfig apply the raw value on top of DefaultConfig and validate them
+func (raw fileConfig) toConfig() (Config, error) {
+	cfg := DefaultConfig
+	var errs []error
+
+	setIfNotNil(&cfg.LogToTerminal, raw.LogToTerminal)
+	setIfNotNil(&cfg.LogToFile, raw.LogToFile)
+	setIfNotNil(&cfg.Location, raw.Location)
+	setIfNotNil(&cfg.FileLogName, raw.FileLogName)
+	setIfNotNil(&cfg.ServiceName, raw.ServiceName)
+	setIfNotNil(&cfg.FileFormat, raw.FileFormat)
+	setIfNotNil(&cfg.MaxAge, raw.MaxAge)
+	setIfNotNil(&cfg.RotationFile, raw.RotationFile)
+	setIfNotNil(&cfg.MaxSize, raw.MaxSize)
+	setIfNotNil(&cfg.MaxBackups, raw.MaxBackups)
+	setIfNotNil(&cfg.MaxTotalSize, raw.MaxTotalSize)
+	setIfNotNil(&cfg.HideSensitiveData, raw.HideSensitiveData)
+	setIfNotNil(&cfg.DisableSubLogs, raw.DisableSubLogs)
+	setIfNotNil(&cfg.MirrorSubLogs, raw.MirrorSubLogs)
+	setIfNotNil(&cfg.DisableTraceLog, raw.DisableTraceLog)
+	setIfNotNil(&cfg.DisableRequestLog, raw.DisableRequestLog)
+
+	if cfg.MaxAge < 0 {
+		errs = append(errs, fmt.Errorf("max_age: must not be negative, got %d", cfg.MaxAge))
+	}
+	if cfg.RotationFile < 0 {
+		errs = append(errs, fmt.Errorf("rotation_file: must not be negative, got %d", cfg.RotationFile))
+	}
+	if cfg.MaxSize < 0 {
+		errs = append(errs, fmt.Errorf("max_size: must not be negative, got %d", cfg.MaxSize))
+	}
+	if cfg.MaxBackups < 0 {
+		errs = append(errs, fmt.Errorf("max_backups: must not be negative, got %d", cfg.MaxBackups))
+	}
+	if cfg.MaxTotalSize < 0 {
+		errs = append(errs, fmt.Errorf("max_total_size: must not be negative, got %d", cfg.MaxTotalSize))
+	}
+
+	if raw.RotationMode != nil {
+		switch mode := RotationMode(strings.ToLower(*raw.RotationMode)); mode {
+		case RotationBuiltin, RotationExternal:
+			cfg.RotationMode = mode
+		case "builtin":
+			cfg.RotationMode = RotationBuiltin
+		default:
+			errs = append(errs, fmt.Errorf("rotation_mode: unknown mode %q, use builtin or external", *raw.RotationMode))
+		}
+	}
+
+	cfg.DirMode = parseFileMode("dir_mode", raw.DirMode, &errs)
+	cfg.FileMode = parseFileMode("file_mode", raw.FileMode, &errs)
+
+	if raw.Compress != nil {
+		switch compress := Compression(strings.ToLower(*raw.Compress)); compress {
+		case CompressNone, CompressGzip, CompressZstd:
+			cfg.Compress = compress
+		default:
+			errs = append(errs, fmt.Errorf("compress: unknown compression %q, use gzip or zstd", *raw.Compress))
+		}
+	}
+
+	if raw.Level != nil {
+		level, err := ParseLevel(*raw.Level)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("level: %w", err))
+		}
+		cfg.Level = level
+		cfg.LevelSet = true
+	}
+
+	if raw.SubLogLevel != nil {
+		level, err := ParseLevel(*raw.SubLogLevel)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("sub_log_level: %w", err))
+		}
+		cfg.SubLogLevel = &level
+	}
+
+	if raw.Format != nil {
+		format, err := parseFormat(*raw.Format)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("format: %w", err))
+		}
+		cfg.Format = format
+	}
+
+	if len(raw.LevelRules) > 0 {
+		cfg.LevelRules = make(map[string]slog.Level)
+		for pattern, name := range raw.LevelRules {
+			level, err := ParseLevel(name)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("level_rules[%s]: %w", pattern, err))
+				continue
+			}
+			cfg.LevelRules[pattern] = level
+		}
+	}
+
+	if raw.Sampling != nil {
+		sampling := &SamplingConfig{RouteRates: raw.Sampling.RouteRates}
+		setIfNotNil(&sampling.Initial, raw.Sampling.Initial)
+		setIfNotNil(&sampling.Thereafter, raw.Sampling.Thereafter)
+		setIfNotNil(&sampling.RequestRate, raw.Sampling.RequestRate)
+		sampling.Tick = parseDuration("sampling.tick", raw.Sampling.Tick, &errs)
+
+		if sampling.Initial < 0 || sampling.Thereafter < 0 {
+			errs = append(errs, errors.New("sampling: initial and thereafter must not be negative"))
+		}
+		if sampling.RequestRate < 0 || sampling.RequestRate > 1 {
+			errs = append(errs, fmt.Errorf("sampling.request_rate: must be between 0 and 1, got %v", sampling.RequestRate))
+		}
+		for route, rate := range sampling.RouteRates {
+			if rate < 0 || rate > 1 {
+				errs = append(errs, fmt.Errorf("sampling.route_rates[%s]: must be between 0 and 1, got %v", route, rate))
+			}
+		}
+		cfg.Sampling = sampling
+	}
+
+	if raw.SubLogLimit != nil {
+		limit := &SubLogLimitConfig{}
+		setIfNotNil(&limit.Head, raw.SubLogLimit.Head)
+		setIfNotNil(&limit.Tail, raw.SubLogLimit.Tail)
+		setIfNotNil(&limit.MaxBytes, raw.SubLogLimit.MaxBytes)
+		setIfNotNil(&limit.MaxMessageSize, raw.SubLogLimit.MaxMessageSize)
+
+		if limit.Head < 0 || limit.Tail < 0 || limit.MaxBytes < 0 || limit.MaxMessageSize < 0 {
+			errs = append(errs, errors.New("sub_log_limit: head, tail, max_bytes and max_message_size must not be negative"))
+		}
+		cfg.SubLogLimit = limit
+	}
+
+	if raw.SubLogRetention != nil {
+		retention := &SubLogRetentionConfig{}
+		setIfNotNil(&retention.MinStatus, raw.SubLogRetention.MinStatus)
+		retention.SlowThreshold = parseDuration("sub_log_retention.slow_threshold", raw.SubLogRetention.SlowThreshold, &errs)
+
+		if retention.MinStatus < 0 {
+			errs = append(errs, fmt.Errorf("sub_log_retention.min_status: must not be negative, got %d", retention.MinStatus))
+		}
+		for route, value := range raw.SubLogRetention.RouteThresholds {
+			threshold := parseDuration(fmt.Sprintf("sub_log_retention.route_thresholds[%s]", route), &value, &errs)
+			if retention.RouteThreshold == nil {
+				retention.RouteThreshold = make(map[string]time.Duration)
+			}
+			retention.RouteThreshold[route] = threshold
+		}
+		cfg.SubLogRetention = retention
+	}
+
+	if raw.Async != nil {
+		async := &AsyncConfig{}
+		setIfNotNil(&async.QueueSize, raw.Async.QueueSize)
+		setIfNotNil(&async.DropWhenFull, raw.Async.DropWhenFull)
+		async.FlushTimeout = parseDuration("async.flush_timeout", raw.Async.FlushTimeout, &errs)
+
+		if async.QueueSize < 0 {
+			errs = append(errs, fmt.Errorf("async.queue_size: must not be negative, got %d", async.QueueSize))
+		}
+		cfg.Async = async
+	}
+
+	sinks := make([]Sink, len(raw.Sinks))
+	for i, rawSink := range raw.Sinks {
+		sinks[i] = rawSink.toSink(fmt.Sprintf("sinks[%d]", i), &errs)
+	}
+
+	// Sinks replace the default terminal output, unless the terminal is asked for explicitly
+	if len(raw.Sinks) > 0 && raw.LogToTerminal == nil && raw.Outputs == nil {
+		cfg.LogToTerminal = false
+	}
+
+	// Output is opened last, so no file is left open when the config is invalid
+	if raw.Outputs != nil && len(errs) == 0 {
+		cfg.LogToTerminal = false
+		var writers []io.Writer
+		for _, output := range raw.Outputs {
+			writer, terminal, err := openOutput(output, cfg)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("outputs: %w", err))
+				continue
+			}
+			if terminal {
+				cfg.LogToTerminal = true
+				continue
+			}
+			if file, ok := writer.(*reopenFile); ok {
+				cfg.outputFiles = append(cfg.outputFiles, file)
+			}
+			writers = append(writers, writer)
+		}
+		if len(writers) == 1 {
+			cfg.CustomWriter = writers[0]
+		} else if len(writers) > 1 {
+			cfg.CustomWriter = io.MultiWriter(writers...)
+		}
+	}
+
+	if len(errs) == 0 {
+		for i := range sinks {
+			writer, _, err := openOutput(raw.Sinks[i].Output, cfg)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("sinks[%d].output: %w", i, err))
+				continue
+			}
+			if file, ok := writer.(*reopenFile); ok {
+				cfg.outputFiles = append(cfg.outputFiles, file)
+			}
+			sinks[i].Writer = writer
+			cfg.Sinks = append(cfg.Sinks, sinks[i])
+		}
+	}
+
+	if len(errs) > 0 {
+		cfg.closeOutputFiles()
+		return Config{}, fmt.Errorf("invalid log config, %w", errors.Join(errs...))
+	}
+	return cfg, nil
+}
+
+// toSink validate the sink setting, the output is opened later by openOutput
+func (raw fileSink) toSink(name string, errs *[]error) Sink {
+	var sink Sink
+	if raw.Output == "" {
+		*errs = append(*errs, fmt.Errorf("%s.output: must be set", name))
+	}
+
+	if raw.Format != nil {
+		format, err := parseFormat(*raw.Format)
+		if err != nil {
+			*errs = append(*errs, fmt.Errorf("%s.format: %w", name, err))
+		}
+		sink.Format = format
+	}
+
+	parseLevel := func(key string, value string) slog.Level {
+		level, err := ParseLevel(value)
+		if err != nil {
+			*errs = append(*errs, fmt.Errorf("%s.%s: %w", name, key, err))
+		}
+		return level
+	}
+
+	switch {
+	case len(raw.Levels) > 0:
+		if raw.MinLevel != nil || raw.MaxLevel != nil {
+			*errs = append(*errs, fmt.Errorf("%s: levels can not be combined with min_level and max_level", name))
+		}
+		levels := make([]slog.Level, len(raw.Levels))
+		for i, level := range raw.Levels {
+			levels[i] = parseLevel("levels", level)
+		}
+		sink.Levels = OnlyLevels(levels...)
+	case raw.MaxLevel != nil:
+		minLevel := slog.Level(math.MinInt)
+		if raw.MinLevel != nil {
+			minLevel = parseLevel("min_level", *raw.MinLevel)
+		}
+		sink.Levels = LevelRange(minLevel, parseLevel("max_level", *raw.MaxLevel))
+	case raw.MinLevel != nil:
+		sink.Levels = MinLevel(parseLevel("min_level", *raw.MinLevel))
+	}
+	return sink
+}
+
+// parseFormat parse output format name case insensitively
+func parseFormat(value string) (Format, error) {
+	switch format := Format(strings.ToLower(value)); format {
+	case FormatJSON, FormatLogfmt, FormatText, FormatConsole:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown format %q, use json, logfmt, text or console", value)
+	}
+}
+
+// openOutput open writer from URL: "stdout", "stderr" or "file:///var/log/app.log".
+// File is opened in append mode and created when not exist, the path is expanded like Config.Location.
+// The file is closed by Logger.Close and opened again by Logger.Reopen.
+func openOutput(rawURL string, cfg Config) (writer io.Writer, terminal bool, err error) {
+	switch strings.ToLower(strings.TrimSpace(rawURL)) {
+	case "stdout":
+		return os.Stdout, true, nil
+	case "stderr":
+		return os.Stderr, false, nil
+	}
+
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid output %q, %w", rawURL, err)
+	}
+	if parsed.Scheme != "file" {
+		return nil, false, fmt.Errorf("unsupported output %q, use stdout, stderr or file:///path", rawURL)
+	}
+
+	path := parsed.Path
+	if parsed.Host != "" && parsed.Host != "localhost" {
+		path = parsed.Host + path // Relative path, example file://log/app.log
+	}
+	if path == "" {
+		return nil, false, fmt.Errorf("invalid output %q, file path is empty", rawURL)
+	}
+	if path, err = expandPath(path, cfg.ServiceName); err != nil {
+		return nil, false, fmt.Errorf("invalid output %q, %w", rawURL, err)
+	}
+
+	file, err := newReopenFile(path, cfg.DirMode, cfg.FileMode)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed open output %q, %w", rawURL, err)
+	}
+	return file, false, nil
+}
+
+// closeOutputFiles close the files opened by LoadConfig or ConfigFromEnv, used when the config can not be built
+func (cfg Config) closeOutputFiles() {
+	for _, file := range cfg.outputFiles {
+		file.Close()
+	}
+}
+
+func setIfNotNil[T any](target *T, value *T) {
+	if value != nil {
+		*target = *value
+	}
+}
+
+// parseFileMode parse octal permission, example "0750"
+func parseFileMode(name string, value *string, errs *[]error) os.FileMode {
+	if value == nil || *value == "" {
+		return 0
+	}
+	mode, err := strconv.ParseUint(*value, 8, 32)
+	if err != nil || mode > 0o777 {
+		*errs = append(*errs, fmt.Errorf("%s: invalid permission %q, use octal like 0755", name, *value))
+		return 0
+	}
+	return os.FileMode(mode)
+}
+
+func parseDuration(name string, value *string, errs *[]error) time.Duration {
+	if value == nil || *value == "" {
+		return 0
+	}
+	duration, err := time.ParseDuration(*value)
+	if err != nil || duration < 0 {
+		*errs = append(*errs, fmt.Errorf("%s: invalid duration %q", name, *value))
+	}
+	return duration
+}
+
+// envReader read typed environment variable, parsing error is collected into errs
+type envReader struct {
+	prefix string
+	errs   *[]error
+}
+
+func (e envReader) lookup(name string) (string, bool) {
+	value, found := os.LookupEnv(e.prefix + name)
+	return strings.TrimSpace(value), found
+}
+
+func (e envReader) string(name string) *string {
+	if value, found := e.lookup(name); found {
+		return &value
+	}
+	return nil
+}
+
+func (e envReader) bool(name string) *bool {
+	value, found := e.lookup(name)
+	if !found {
+		return nil
+	}
+	result, err := strconv.ParseBool(value)
+	if err != nil {
+		*e.errs = append(*e.errs, fmt.Errorf("%s%s: invalid boolean %q", e.prefix, name, value))
+		return nil
+	}
+	return &result
+}
+
+func (e envReader) int(name string) *int {
+	value, found := e.lookup(name)
+	if !found {
+		return nil
+	}
+	result, err := strconv.Atoi(value)
+	if err != nil {
+		*e.errs = append(*e.errs, fmt.Errorf("%s%s: invalid number %q", e.prefix, name, value))
+		return nil
+	}
+	return &result
+}
+
+func (e envReader) float(name string) *float64 {
+	value, found := e.lookup(name)
+	if !found {
+		return nil
+	}
+	result, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		*e.errs = append(*e.errs, fmt.Errorf("%s%s: invalid number %q", e.prefix, name, value))
+		return nil
+	}
+	return &result
+}
+
+// list read comma separated value
+func (e envReader) list(name string) []string {
+	value, found := e.lookup(name)
+	if !found {
+		return nil
+	}
+	result := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// pairs read comma separated key=value, example "repository/*=WARN,usecase/payment=DEBUG"
+func (e envReader) pairs(name string) map[string]string {
+	items := e.list(name)
+	if items == nil {
+		return nil
+	}
+	result := make(map[string]string, len(items))
+	for _, item := range items {
+		key, value, found := strings.Cut(item, "=")
+		if !found || strings.TrimSpace(key) == "" {
+			*e.errs = append(*e.errs, fmt.Errorf("%s%s: invalid pair %q, expected key=value", e.prefix, name, item))
+			continue
+		}
+		result[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return result
+}
@@ -0,0 +1,118 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "log.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestConfigFileOutputReopenAndClose(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "app.log")
+	sink := filepath.Join(dir, "error.log")
+	path := writeConfigFile(t, "level: debug\noutputs: [\"file://"+output+"\"]\nsinks:\n  - output: file://"+sink+"\n    min_level: error\n")
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger, err := cfg.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger.Error("before rotate")
+
+	// Simulate logrotate moving both files
+	for _, file := range []string{output, sink} {
+		if err := os.Rename(file, file+".1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := logger.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	logger.Error("after rotate")
+
+	for _, file := range []string{output, sink} {
+		if content := readFile(t, file+".1"); !strings.Contains(content, "before rotate") || strings.Contains(content, "after rotate") {
+			t.Errorf("%s.1: expected only the line before rotate, got %q", file, content)
+		}
+		if content := readFile(t, file); !strings.Contains(content, "after rotate") || strings.Contains(content, "before rotate") {
+			t.Errorf("%s: expected only the line after rotate, got %q", file, content)
+		}
+	}
+
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+	logger.Error("after close")
+	if content := readFile(t, output); strings.Contains(content, "after close") {
+		t.Errorf("expected no write after Close, got %q", content)
+	}
+}
+
+func TestConfigInvalidOutputCloseOpenedFiles(t *testing.T) {
+	if _, err := os.Stat("/proc/self/fd"); err != nil {
+		t.Skip("open file count is not available")
+	}
+	countFiles := func() int {
+		entries, err := os.ReadDir("/proc/self/fd")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(entries)
+	}
+
+	output := filepath.Join(t.TempDir(), "app.log")
+	path := writeConfigFile(t, "outputs: [\"file://"+output+"\", \"file:///dev/null/app.log\"]\n")
+
+	before := countFiles()
+	if _, err := LoadConfig(path); err == nil {
+		t.Fatal("expected error for output inside /dev/null")
+	}
+	if after := countFiles(); after != before {
+		t.Fatalf("expected opened output to be closed, %d files open before and %d after", before, after)
+	}
+}
+
+func TestConfigInvalidBuildCloseOpenedFiles(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "app.log")
+	cfg, err := LoadConfig(writeConfigFile(t, "outputs: [\"file://"+output+"\"]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.RotationMode = "unknown"
+	cfg.LogToFile = true
+	cfg.Location = t.TempDir()
+	if _, err := cfg.Build(); err == nil {
+		t.Fatal("expected error for unknown rotation mode")
+	}
+	for _, file := range cfg.outputFiles {
+		if _, err := file.Write([]byte("line\n")); err != ErrClosed {
+			t.Fatalf("expected output closed after failed Build, got %v", err)
+		}
+	}
+}
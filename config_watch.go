@@ -0,0 +1,102 @@
+package log
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"sync"
+	"time"
+)
+
+// WatchConfig poll the config file every interval and apply level, level rules, TRACE and REQUEST
+// switches and sensitive data masking changes to the logger while it is running. Other fields need a restart.
+// Invalid file is reported as an error log and the current setting is kept.
+func (l *Logger) WatchConfig(path string, interval time.Duration) (stop func(), err error) {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	current, err := loadWatchedConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	lastModified, err := fileVersion(path)
+	if err != nil {
+		return nil, err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			modified, err := fileVersion(path)
+			if err != nil || modified == lastModified {
+				continue
+			}
+			lastModified = modified
+
+			updated, err := loadWatchedConfig(path)
+			if err != nil {
+				l.logWithCaller(LevelError, fmt.Sprintf("failed reload log config, %s", err.Error()), 1)
+				continue
+			}
+
+			if updated.Level != current.Level {
+				l.SetLevel(updated.Level)
+			}
+			if !maps.Equal(updated.LevelRules, current.LevelRules) {
+				l.SetLevelRules(updated.LevelRules)
+			}
+			if updated.DisableTraceLog != current.DisableTraceLog {
+				l.SetTraceEnabled(!updated.DisableTraceLog)
+			}
+			if updated.DisableRequestLog != current.DisableRequestLog {
+				l.SetRequestEnabled(!updated.DisableRequestLog)
+			}
+			if updated.HideSensitiveData != current.HideSensitiveData {
+				l.SetHideSensitiveData(updated.HideSensitiveData)
+			}
+			current = updated
+		}
+	}()
+
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }, nil
+}
+
+// loadWatchedConfig load config without opening the outputs, they are not reloaded
+func loadWatchedConfig(path string) (Config, error) {
+	raw, err := readConfigFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+	raw.Outputs, raw.Sinks = nil, nil
+	return raw.toConfig()
+}
+
+// fileVersion identify file content change by modification time and size
+func fileVersion(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d-%d", info.ModTime().UnixNano(), info.Size()), nil
+}
+
+// SetHideSensitiveData enable or disable masking struct field with tag `log:"hide"` at runtime
+func (l *Logger) SetHideSensitiveData(enable bool) {
+	l.hideSensitiveData.Store(enable)
+}
+
+// WatchConfig watch the config file and apply live changes to the default logger
+func WatchConfig(path string, interval time.Duration) (stop func(), err error) {
+	return Default().WatchConfig(path, interval)
+}
@@ -0,0 +1,179 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+
+	"go.uber.org/zap/zapcore"
+)
+
+var logFieldsKey contextKey = 1 // logRequestKey use the zero value
+
+// WithFields return a copy of ctx carrying request scoped fields, they are added to every Ctx log call.
+// Fields from the parent context are kept.
+func WithFields(ctx context.Context, keysAndValues ...any) context.Context {
+	fields := append(append([]slog.Attr{}, fieldsFromContext(ctx)...), argsToAttrs(keysAndValues)...)
+	return context.WithValue(ctx, logFieldsKey, fields)
+}
+
+func fieldsFromContext(ctx context.Context) []slog.Attr {
+	fields, _ := ctx.Value(logFieldsKey).([]slog.Attr)
+	return fields
+}
+
+func (l *Logger) DebugCtx(ctx context.Context, i ...any) {
+	l.logCtx(ctx, LevelDebug, formatMultipleArguments(i), nil)
+}
+
+func (l *Logger) DebugfCtx(ctx context.Context, format string, i ...any) {
+	l.logCtx(ctx, LevelDebug, fmt.Sprintf(format, i...), nil)
+}
+
+func (l *Logger) DebugwCtx(ctx context.Context, msg string, keysAndValues ...any) {
+	l.logCtx(ctx, LevelDebug, msg, argsToAttrs(keysAndValues))
+}
+
+func (l *Logger) InfoCtx(ctx context.Context, i ...any) {
+	l.logCtx(ctx, LevelInfo, formatMultipleArguments(i), nil)
+}
+
+func (l *Logger) InfofCtx(ctx context.Context, format string, i ...any) {
+	l.logCtx(ctx, LevelInfo, fmt.Sprintf(format, i...), nil)
+}
+
+func (l *Logger) InfowCtx(ctx context.Context, msg string, keysAndValues ...any) {
+	l.logCtx(ctx, LevelInfo, msg, argsToAttrs(keysAndValues))
+}
+
+func (l *Logger) WarnCtx(ctx context.Context, i ...any) {
+	l.logCtx(ctx, LevelWarning, formatMultipleArguments(i), nil)
+}
+
+func (l *Logger) WarnfCtx(ctx context.Context, format string, i ...any) {
+	l.logCtx(ctx, LevelWarning, fmt.Sprintf(format, i...), nil)
+}
+
+func (l *Logger) WarnwCtx(ctx context.Context, msg string, keysAndValues ...any) {
+	l.logCtx(ctx, LevelWarning, msg, argsToAttrs(keysAndValues))
+}
+
+func (l *Logger) ErrorCtx(ctx context.Context, i ...any) {
+	l.logCtx(ctx, LevelError, formatMultipleArguments(i), nil)
+}
+
+func (l *Logger) ErrorfCtx(ctx context.Context, format string, i ...any) {
+	l.logCtx(ctx, LevelError, fmt.Sprintf(format, i...), nil)
+}
+
+func (l *Logger) ErrorwCtx(ctx context.Context, msg string, keysAndValues ...any) {
+	l.logCtx(ctx, LevelError, msg, argsToAttrs(keysAndValues))
+}
+
+func (l *Logger) FatalCtx(ctx context.Context, i ...any) {
+	l.logCtx(ctx, LevelFatal, formatMultipleArguments(i), nil)
+	l.exit(1)
+}
+
+func (l *Logger) FatalfCtx(ctx context.Context, format string, i ...any) {
+	l.logCtx(ctx, LevelFatal, fmt.Sprintf(format, i...), nil)
+	l.exit(1)
+}
+
+func (l *Logger) FatalwCtx(ctx context.Context, msg string, keysAndValues ...any) {
+	l.logCtx(ctx, LevelFatal, msg, argsToAttrs(keysAndValues))
+	l.exit(1)
+}
+
+// Package level Ctx functions use the default logger and stamp the traceID, route and fields from ctx.
+
+func DebugCtx(ctx context.Context, i ...any) {
+	Default().logCtx(ctx, LevelDebug, formatMultipleArguments(i), nil)
+}
+
+func DebugfCtx(ctx context.Context, format string, i ...any) {
+	Default().logCtx(ctx, LevelDebug, fmt.Sprintf(format, i...), nil)
+}
+
+func DebugwCtx(ctx context.Context, msg string, keysAndValues ...any) {
+	Default().logCtx(ctx, LevelDebug, msg, argsToAttrs(keysAndValues))
+}
+
+func InfoCtx(ctx context.Context, i ...any) {
+	Default().logCtx(ctx, LevelInfo, formatMultipleArguments(i), nil)
+}
+
+func InfofCtx(ctx context.Context, format string, i ...any) {
+	Default().logCtx(ctx, LevelInfo, fmt.Sprintf(format, i...), nil)
+}
+
+func InfowCtx(ctx context.Context, msg string, keysAndValues ...any) {
+	Default().logCtx(ctx, LevelInfo, msg, argsToAttrs(keysAndValues))
+}
+
+func WarnCtx(ctx context.Context, i ...any) {
+	Default().logCtx(ctx, LevelWarning, formatMultipleArguments(i), nil)
+}
+
+func WarnfCtx(ctx context.Context, format string, i ...any) {
+	Default().logCtx(ctx, LevelWarning, fmt.Sprintf(format, i...), nil)
+}
+
+func WarnwCtx(ctx context.Context, msg string, keysAndValues ...any) {
+	Default().logCtx(ctx, LevelWarning, msg, argsToAttrs(keysAndValues))
+}
+
+func ErrorCtx(ctx context.Context, i ...any) {
+	Default().logCtx(ctx, LevelError, formatMultipleArguments(i), nil)
+}
+
+func ErrorfCtx(ctx context.Context, format string, i ...any) {
+	Default().logCtx(ctx, LevelError, fmt.Sprintf(format, i...), nil)
+}
+
+func ErrorwCtx(ctx context.Context, msg string, keysAndValues ...any) {
+	Default().logCtx(ctx, LevelError, msg, argsToAttrs(keysAndValues))
+}
+
+func FatalCtx(ctx context.Context, i ...any) {
+	logger := Default()
+	logger.logCtx(ctx, LevelFatal, formatMultipleArguments(i), nil)
+	logger.exit(1)
+}
+
+func FatalfCtx(ctx context.Context, format string, i ...any) {
+	logger := Default()
+	logger.logCtx(ctx, LevelFatal, fmt.Sprintf(format, i...), nil)
+	logger.exit(1)
+}
+
+func FatalwCtx(ctx context.Context, msg string, keysAndValues ...any) {
+	logger := Default()
+	logger.logCtx(ctx, LevelFatal, msg, argsToAttrs(keysAndValues))
+	logger.exit(1)
+}
+
+// logCtx write a global log with the traceID, route and fields from ctx.
+// When MirrorSubLogs is enabled the entry is also appended to the request sub-logs.
+func (l *Logger) logCtx(ctx context.Context, level slog.Level, msg string, fields []slog.Attr) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	caller := zapcore.NewEntryCaller(runtime.Caller(globalSkipLevel))
+	fields = append(append([]slog.Attr{}, fieldsFromContext(ctx)...), fields...)
+	requestLog, hasRequest := ctx.Value(logRequestKey).(*request)
+
+	if level >= l.handlerLevel.Level() && l.enabled(level, caller.File) && l.sampler.allowGlobal(level, msg) {
+		entry := &Entry{Level: level, Caller: caller.TrimmedPath(), Message: msg, Fields: fields}
+		if hasRequest {
+			entry.TraceID, entry.Route = requestLog.traceID, requestLog.Route
+		}
+		l.write(ctx, entry)
+	}
+
+	if hasRequest && l.mirrorSubLogs && !requestLog.logger.disableSubLogs {
+		requestLog.record(level, caller, msg, fields)
+	}
+}
@@ -0,0 +1,72 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestLogCtx(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        Config
+		withRequest   bool
+		expectSubLogs int
+	}{
+		{"without request", Config{}, false, 0},
+		{"with request", Config{}, true, 0},
+		{"mirror sub-logs", Config{MirrorSubLogs: true}, true, 1},
+		{"mirror with disabled sub-logs", Config{MirrorSubLogs: true, DisableSubLogs: true}, true, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			logger, recorder := newRecordingLogger(t, test.config)
+
+			ctx := WithFields(context.Background(), "tenant", "acme")
+			ctx = WithFields(ctx, "userID", 10)
+			req := logger.NewRequest()
+			req.Route = "/users/:id"
+			if test.withRequest {
+				ctx = req.SaveToContext(ctx)
+			}
+
+			logger.InfowCtx(ctx, "user updated", "field", "email")
+			req.Save()
+
+			requests := recorder.requests(t, logger)
+			recorder.mu.Lock()
+			entry := recorder.entries[0]
+			recorder.mu.Unlock()
+
+			if entry.Message != "user updated" || !strings.Contains(entry.Caller, "context_test.go") {
+				t.Fatalf("expected global entry with the caller, got %+v", entry)
+			}
+			if fields := fmt.Sprint(attrsToMap(entry.Fields)); fields != "map[field:email tenant:acme userID:10]" {
+				t.Fatalf("expected context and call fields, got %s", fields)
+			}
+			if test.withRequest && (entry.TraceID != req.TraceID() || entry.Route != "/users/:id") {
+				t.Fatalf("expected traceID and route from the request, got %q %q", entry.TraceID, entry.Route)
+			}
+			if !test.withRequest && entry.TraceID != "" {
+				t.Fatalf("expected no traceID without request, got %q", entry.TraceID)
+			}
+			if len(requests) != 1 || len(requests[0].SubLogs) != test.expectSubLogs {
+				t.Fatalf("expected %d mirrored sub-logs, got %+v", test.expectSubLogs, requests)
+			}
+		})
+	}
+}
+
+func TestLogCtxLevelAndNilContext(t *testing.T) {
+	logger, recorder := newRecordingLogger(t, Config{Level: LevelWarning})
+
+	logger.InfoCtx(nil, "skipped")
+	logger.ErrorfCtx(nil, "failed %d", 1)
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	if len(recorder.entries) != 1 || recorder.entries[0].Message != "failed 1" {
+		t.Fatalf("expected only the ERROR entry, got %+v", recorder.entries)
+	}
+}
@@ -0,0 +1,169 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+type (
+	// Entry is a typed log entry passed to hooks before it is written.
+	// Request is set for REQUEST entry and Trace is set for TRACE entry.
+	Entry struct {
+		Time    time.Time
+		Level   slog.Level
+		Caller  string
+		TraceID string
+		Route   string
+		Message string
+		Fields  []slog.Attr // Structured fields written after the message
+		Request *RequestData
+		Trace   *TraceData
+
+		noTime bool // Keep the zero Time unset, a slog.Record without time is written without time
+	}
+
+	// RequestData hold the request information of a REQUEST entry
+	RequestData struct {
+		IP              string
+		Method          string
+		URL             string
+		Route           string
+		StatusCode      int
+		Duration        time.Duration
+		RequestHeader   any
+		RequestBody     any
+		ResponseHeader  any
+		ResponseBody    any
+		ExtraData       map[string]any
+		SubLogs         []SubLog
+		SubLogDropped   int  // Sub-logs dropped by Config.SubLogLimit
+		Truncated       bool // Sub-log dropped or message cut by Config.SubLogLimit
+		SubLogDiscarded int  // DEBUG and INFO sub-logs left out by Config.SubLogRetention
+	}
+
+	// TraceData hold the outbound call information of a TRACE entry
+	TraceData struct {
+		Method         string
+		URL            string
+		StatusCode     int
+		Duration       time.Duration
+		RequestHeader  any
+		RequestBody    any
+		ResponseHeader any
+		ResponseBody   any
+	}
+
+	// Hook inspect or change every entry before it is written.
+	// Return false to drop the entry, the remaining hooks are not called.
+	Hook interface {
+		Fire(entry *Entry) bool
+	}
+
+	// HookFunc is an adapter to use ordinary function as Hook
+	HookFunc func(entry *Entry) bool
+)
+
+func (f HookFunc) Fire(entry *Entry) bool {
+	return f(entry)
+}
+
+// AddHook register hooks, they run in registration order for global, REQUEST and TRACE entries
+func (l *Logger) AddHook(hooks ...Hook) {
+	l.hookMu.Lock()
+	defer l.hookMu.Unlock()
+
+	updated := append(append([]Hook{}, l.getHooks()...), hooks...)
+	l.hooks.Store(&updated)
+}
+
+func (l *Logger) getHooks() []Hook {
+	if hooks := l.hooks.Load(); hooks != nil {
+		return *hooks
+	}
+	return nil
+}
+
+// write run the hooks and pass the entry to the handler, level filtering must be done by the caller
+func (l *Logger) write(ctx context.Context, entry *Entry) {
+	if !l.categoryEnabled(entry.Level) {
+		return
+	}
+	if entry.Time.IsZero() && !entry.noTime {
+		entry.Time = time.Now()
+	}
+
+	for _, hook := range l.getHooks() {
+		if !hook.Fire(entry) {
+			return
+		}
+	}
+
+	handler := l.slog.Handler()
+	if !handler.Enabled(ctx, entry.Level) {
+		return
+	}
+	if l.ring != nil {
+		l.ring.add(entry)
+	}
+
+	record := slog.NewRecord(entry.Time, entry.Level, "", 0)
+	record.AddAttrs(entry.attrs()...)
+	handler.Handle(ctx, record)
+}
+
+// attrs convert entry into output attributes, keeping the key order of each entry type
+func (e *Entry) attrs() []slog.Attr {
+	attrs := make([]slog.Attr, 0, 16)
+	if e.Caller != "" {
+		attrs = append(attrs, slog.String("caller", e.Caller))
+	}
+	if e.TraceID != "" {
+		attrs = append(attrs, slog.String(traceID, e.TraceID))
+	}
+
+	switch {
+	case e.Request != nil:
+		attrs = append(attrs,
+			slog.String("ip", e.Request.IP),
+			slog.String("method", e.Request.Method),
+			slog.String("url", e.Request.URL),
+			slog.Int("statusCode", e.Request.StatusCode),
+			slog.Int64("totalDuration", e.Request.Duration.Milliseconds()),
+			slog.Any("requestHeader", e.Request.RequestHeader),
+			slog.Any("requestBody", e.Request.RequestBody),
+			slog.Any("responseHeader", e.Request.ResponseHeader),
+			slog.Any("responseBody", e.Request.ResponseBody),
+			slog.Any("extraData", e.Request.ExtraData),
+			slog.Any("subLog", e.Request.SubLogs),
+		)
+		if e.Request.Truncated {
+			attrs = append(attrs, slog.Int("subLogDropped", e.Request.SubLogDropped), slog.Bool("truncated", true))
+		}
+		if e.Request.SubLogDiscarded > 0 {
+			attrs = append(attrs, slog.Int("subLogDiscarded", e.Request.SubLogDiscarded))
+		}
+
+	case e.Trace != nil:
+		attrs = append(attrs,
+			slog.String("method", e.Trace.Method),
+			slog.String("url", e.Trace.URL),
+			slog.Int("statusCode", e.Trace.StatusCode),
+			slog.Int64("totalDuration", e.Trace.Duration.Milliseconds()),
+			slog.Any("requestHeader", e.Trace.RequestHeader),
+			slog.Any("requestBody", e.Trace.RequestBody),
+			slog.Any("responseHeader", e.Trace.ResponseHeader),
+			slog.Any("responseBody", e.Trace.ResponseBody),
+		)
+
+	default:
+		if e.Route != "" {
+			attrs = append(attrs, slog.String("route", e.Route))
+		}
+	}
+
+	if e.Message != "" {
+		attrs = append(attrs, slog.String("msg", e.Message))
+	}
+	return append(attrs, e.Fields...)
+}
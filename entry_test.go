@@ -0,0 +1,73 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHooks(t *testing.T) {
+	var buf bytes.Buffer
+	var calls []string
+	logger := newTestLogger(t, &buf, Config{Hooks: []Hook{
+		HookFunc(func(entry *Entry) bool {
+			calls = append(calls, "redact "+entry.Message)
+			entry.Message = strings.ReplaceAll(entry.Message, "secret", "***")
+			return true
+		}),
+		HookFunc(func(entry *Entry) bool {
+			calls = append(calls, "drop "+entry.Message)
+			return !strings.Contains(entry.Message, "health")
+		}),
+	}})
+	logger.AddHook(HookFunc(func(entry *Entry) bool {
+		calls = append(calls, "last "+entry.Message)
+		return true
+	}))
+
+	logger.Info("password secret")
+	logger.Info("health check")
+
+	expected := []string{
+		"redact password secret", "drop password ***", "last password ***",
+		"redact health check", "drop health check",
+	}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Fatalf("expected hooks in registration order, stopping at the drop\nexpected %v\ngot      %v", expected, calls)
+	}
+	if output := buf.String(); !strings.Contains(output, "password ***") || strings.Contains(output, "secret") || strings.Contains(output, "health") {
+		t.Fatalf("expected changed entry written and dropped entry skipped, got %q", output)
+	}
+}
+
+func TestHooksRequestAndTrace(t *testing.T) {
+	var buf syncWriter
+	output := &strings.Builder{}
+	buf.w = output
+	logger := newTestLogger(t, &buf, Config{Hooks: []Hook{HookFunc(func(entry *Entry) bool {
+		switch {
+		case entry.Request != nil:
+			entry.Request.ExtraData["hooked"] = true
+		case entry.Trace != nil:
+			entry.Trace.URL = "https://api.local/redacted"
+		}
+		return true
+	})}})
+
+	req := logger.NewRequest()
+	trace := NewTrace(http.MethodGet, "https://api.local/users?token=secret", nil, nil, false)
+	trace.Save(req.SaveToContext(context.Background()), &http.Response{StatusCode: http.StatusOK, Header: http.Header{}})
+	req.Save()
+	if err := logger.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	buf.mu.Lock()
+	defer buf.mu.Unlock()
+	if !strings.Contains(output.String(), `"hooked":true`) || !strings.Contains(output.String(), "https://api.local/redacted") || strings.Contains(output.String(), "token=secret") {
+		t.Fatalf("expected hooks to change REQUEST and TRACE entries, got %q", output.String())
+	}
+}
@@ -31,13 +31,13 @@ func main() {
 		time.Sleep(100 * time.Millisecond) // Simulate a process
 
 		// Add some extra data
-		log.Context(ctx).ExtraData["userData"] = struct {
+		log.Context(ctx).SetExtra("userData", struct {
 			Name string
 			Age  int
 		}{
 			Name: "Bob",
 			Age:  29,
-		}
+		})
 
 		// Log Request
 		log.Context(ctx).Debug("Testing Log Request Debug")
@@ -0,0 +1,74 @@
+package log
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+)
+
+// exitTimeout is the maximum time Fatal wait for pending request logs before exiting
+const exitTimeout = 5 * time.Second
+
+// RegisterExitHook add a function called by Fatal before the process exit.
+// Hooks run in registration order, after that pending request logs are written and the output is flushed.
+func (l *Logger) RegisterExitHook(hook func()) {
+	l.exitMu.Lock()
+	defer l.exitMu.Unlock()
+	l.exitHooks = append(l.exitHooks, hook)
+}
+
+// SetExitFunc replace the function called by Fatal, useful for testing fatal code path
+func (l *Logger) SetExitFunc(exitFunc func(code int)) {
+	l.exitMu.Lock()
+	defer l.exitMu.Unlock()
+	l.exitFunc = exitFunc
+}
+
+// exit run the exit hooks, wait for pending request logs, flush the output and then exit.
+// The output is not closed, so logging still work when exitFunc does not stop the process.
+func (l *Logger) exit(code int) {
+	l.exitWith(nil, code)
+}
+
+// exitWith is exit calling override instead of the logger exit function when it is not nil
+func (l *Logger) exitWith(override func(code int), code int) {
+	l.exitMu.Lock()
+	hooks := append([]func(){}, l.exitHooks...)
+	exitFunc := l.exitFunc
+	if override != nil {
+		exitFunc = override
+	}
+	l.exitMu.Unlock()
+
+	for _, hook := range hooks {
+		hook()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), exitTimeout)
+	defer cancel()
+	l.waitPending(ctx)
+	l.Flush()
+
+	if exitFunc == nil {
+		exitFunc = os.Exit
+	}
+	exitFunc(code)
+}
+
+// fatalBuildError write the Build error of New as FATAL with the default logger and exit through its exit hooks.
+// Config.ExitFunc is used when set, otherwise the exit function of the default logger.
+func fatalBuildError(cfg Config, err error) {
+	logger := Default()
+	if logger == nil {
+		log.Fatal(err.Error()) // Building the first default logger, nothing to report with yet
+	}
+
+	logger.logWithCaller(LevelFatal, err.Error(), 3) // logWithCaller -> fatalBuildError -> New -> caller
+	logger.exitWith(cfg.ExitFunc, 1)
+}
+
+// RegisterExitHook add a function called by Fatal of the default logger before the process exit
+func RegisterExitHook(hook func()) {
+	Default().RegisterExitHook(hook)
+}
@@ -0,0 +1,113 @@
+package log
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// exitRecorder keep the exit codes and the order of the exit hooks
+type exitRecorder struct {
+	calls []string
+}
+
+func (e *exitRecorder) hook(name string) func() {
+	return func() { e.calls = append(e.calls, name) }
+}
+
+func (e *exitRecorder) exit(code int) {
+	e.calls = append(e.calls, fmt.Sprintf("exit %d", code))
+}
+
+func TestFatalExitPath(t *testing.T) {
+	exits := &exitRecorder{}
+	logger, recorder := newRecordingLogger(t, Config{ExitFunc: exits.exit})
+	logger.RegisterExitHook(exits.hook("first"))
+	logger.RegisterExitHook(exits.hook("second"))
+
+	req := logger.NewRequest()
+	req.Save()
+	logger.Fatalf("cannot start %s", "server")
+
+	if expected := []string{"first", "second", "exit 1"}; !reflect.DeepEqual(exits.calls, expected) {
+		t.Fatalf("expected %v, got %v", expected, exits.calls)
+	}
+
+	// Pending request is written before exit, without waiting in the test
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	if len(recorder.entries) != 2 || recorder.entries[0].Message != "cannot start server" || recorder.entries[1].Request == nil {
+		t.Fatalf("expected FATAL entry and the pending REQUEST entry written before exit, got %+v", recorder.entries)
+	}
+}
+
+func TestSetExitFunc(t *testing.T) {
+	exits := &exitRecorder{}
+	logger, _ := newRecordingLogger(t, Config{})
+	logger.SetExitFunc(exits.exit)
+
+	logger.Fatal("stop")
+	if !reflect.DeepEqual(exits.calls, []string{"exit 1"}) {
+		t.Fatalf("expected replaced exit function called, got %v", exits.calls)
+	}
+}
+
+func TestNewBuildErrorUseExitPath(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      Config
+		defaultExit bool
+	}{
+		{"config exit func", Config{Sinks: []Sink{{}}, ExitFunc: func(int) {}}, false},
+		{"default logger exit func", Config{Sinks: []Sink{{}}}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			previous := Default()
+			t.Cleanup(func() { SetDefault(previous) })
+
+			exits := &exitRecorder{}
+			defaultLogger, recorder := newRecordingLogger(t, Config{ExitFunc: exits.exit})
+			defaultLogger.RegisterExitHook(exits.hook("hook"))
+			SetDefault(defaultLogger)
+
+			exited := false
+			if !test.defaultExit {
+				test.config.ExitFunc = func(code int) { exited = code == 1 }
+			}
+
+			if logger := New(test.config); logger != nil {
+				t.Fatal("expected nil logger when the exit function return")
+			}
+			if test.defaultExit && !reflect.DeepEqual(exits.calls, []string{"hook", "exit 1"}) {
+				t.Fatalf("expected exit hooks and default exit function, got %v", exits.calls)
+			}
+			if !test.defaultExit && (!exited || !reflect.DeepEqual(exits.calls, []string{"hook"})) {
+				t.Fatalf("expected exit hooks and Config.ExitFunc, got %v exited %v", exits.calls, exited)
+			}
+
+			recorder.mu.Lock()
+			defer recorder.mu.Unlock()
+			if len(recorder.entries) != 1 || recorder.entries[0].Level != LevelFatal || !strings.Contains(recorder.entries[0].Message, "sink 0 has no writer") {
+				t.Fatalf("expected the build error written as FATAL, got %+v", recorder.entries)
+			}
+			if !strings.Contains(recorder.entries[0].Caller, "exit_test.go") {
+				t.Fatalf("expected caller of New, got %q", recorder.entries[0].Caller)
+			}
+		})
+	}
+}
+
+func TestInitWithConfigBuildErrorKeepDefault(t *testing.T) {
+	previous := Default()
+	t.Cleanup(func() { SetDefault(previous) })
+
+	logger, _ := newRecordingLogger(t, Config{ExitFunc: func(int) {}})
+	SetDefault(logger)
+
+	InitWithConfig(Config{Sinks: []Sink{{}}})
+	if Default() != logger {
+		t.Fatal("expected the default logger kept when the exit function return")
+	}
+}
@@ -86,7 +86,7 @@ func (l logExtension) Warn(ctx context.Context, msg string, data ...any) {
 // Error print error messages
 func (l logExtension) Error(ctx context.Context, msg string, data ...any) {
 	if l.LogLevel >= logger.Error {
-		log.Context(ctx).SubLog(l.getCallerLocation(), fmt.Sprintf(l.errStr+msg, data...))
+		log.Context(ctx).SubLogError(l.getCallerLocation(), fmt.Sprintf(l.errStr+msg, data...))
 	}
 }
 
@@ -104,9 +104,9 @@ func (l logExtension) Trace(ctx context.Context, begin time.Time, fc func() (str
 	case err != nil && l.LogLevel >= logger.Error && (!errors.Is(err, ErrRecordNotFound) || !l.IgnoreRecordNotFoundError):
 		sql, rows := fc()
 		if rows == -1 {
-			log.Context(ctx).SubLog(level, fmt.Sprintf(l.traceErrStr, err, duration, "-", sql))
+			log.Context(ctx).SubLogError(level, fmt.Sprintf(l.traceErrStr, err, duration, "-", sql))
 		} else {
-			log.Context(ctx).SubLog(level, fmt.Sprintf(l.traceErrStr, err, duration, rows, sql))
+			log.Context(ctx).SubLogError(level, fmt.Sprintf(l.traceErrStr, err, duration, rows, sql))
 		}
 
 	case elapsed > l.SlowThreshold && l.SlowThreshold != 0 && l.LogLevel >= logger.Warn:
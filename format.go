@@ -0,0 +1,252 @@
+package log
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Format is the encoder used for writing log entries
+type Format string
+
+const (
+	FormatJSON    Format = "json"    // One JSON object per line
+	FormatLogfmt  Format = "logfmt"  // key=value pairs, nested value encoded as JSON
+	FormatText    Format = "text"    // Go standard library slog text output
+	FormatConsole Format = "console" // Human friendly colored output for local development
+)
+
+// newHandler create slog handler for the given format, unknown format will fallback to JSON
+func newHandler(format Format, w io.Writer, opts *slog.HandlerOptions) slog.Handler {
+	switch format {
+	case FormatLogfmt:
+		return newEncoderHandler(w, opts, encodeLogfmt)
+	case FormatText:
+		return slog.NewTextHandler(w, opts)
+	case FormatConsole:
+		encoder := encodeConsole
+		if !isTerminal(w) {
+			encoder = encodePlainConsole
+		}
+		return newEncoderHandler(w, opts, encoder)
+	default:
+		return slog.NewJSONHandler(w, opts)
+	}
+}
+
+// isTerminal report whether the final output of w is a character device like a TTY
+func isTerminal(w io.Writer) bool {
+	if async, ok := w.(*asyncWriter); ok {
+		w = async.out
+	}
+
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+type (
+	// encodeFunc write a single resolved record into buf
+	encodeFunc func(buf *strings.Builder, r encodedRecord)
+
+	// encodedRecord is a record with all attributes resolved and flattened to the top level
+	encodedRecord struct {
+		time  time.Time
+		level slog.Level
+		msg   string
+		attrs []slog.Attr
+	}
+
+	// encoderHandler is a slog.Handler shared by the non standard library formats
+	encoderHandler struct {
+		mu     *sync.Mutex
+		w      io.Writer
+		opts   slog.HandlerOptions
+		encode encodeFunc
+		attrs  []slog.Attr // Attributes added by WithAttrs, already prefixed with groups
+		groups []string
+	}
+)
+
+func newEncoderHandler(w io.Writer, opts *slog.HandlerOptions, encode encodeFunc) *encoderHandler {
+	h := &encoderHandler{mu: new(sync.Mutex), w: w, encode: encode}
+	if opts != nil {
+		h.opts = *opts
+	}
+	return h
+}
+
+func (h *encoderHandler) Enabled(_ context.Context, level slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if h.opts.Level != nil {
+		minLevel = h.opts.Level.Level()
+	}
+	return level >= minLevel
+}
+
+func (h *encoderHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = append(append([]slog.Attr{}, h.attrs...), h.prefixAttrs(attrs)...)
+	return &clone
+}
+
+func (h *encoderHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.groups = append(append([]string{}, h.groups...), name)
+	return &clone
+}
+
+func (h *encoderHandler) Handle(_ context.Context, r slog.Record) error {
+	record := encodedRecord{time: r.Time, level: r.Level, msg: r.Message}
+	record.attrs = append(record.attrs, h.attrs...)
+
+	var attrs []slog.Attr
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	record.attrs = append(record.attrs, h.prefixAttrs(attrs)...)
+
+	buf := new(strings.Builder)
+	h.encode(buf, record)
+	buf.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, buf.String())
+	return err
+}
+
+// prefixAttrs resolve attributes, flatten group values and prefix the key with the current groups
+func (h *encoderHandler) prefixAttrs(attrs []slog.Attr) []slog.Attr {
+	var result []slog.Attr
+	prefix := strings.Join(h.groups, ".")
+	for _, a := range attrs {
+		result = append(result, flattenAttr(prefix, a, h.opts.ReplaceAttr, h.groups)...)
+	}
+	return result
+}
+
+func flattenAttr(prefix string, a slog.Attr, replace func([]string, slog.Attr) slog.Attr, groups []string) []slog.Attr {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() == slog.KindGroup {
+		groupPrefix := a.Key
+		if prefix != "" && a.Key != "" {
+			groupPrefix = prefix + "." + a.Key
+		} else if a.Key == "" {
+			groupPrefix = prefix // Inline group without key
+		}
+
+		var result []slog.Attr
+		for _, child := range a.Value.Group() {
+			childGroups := groups
+			if a.Key != "" {
+				childGroups = append(append([]string{}, groups...), a.Key)
+			}
+			result = append(result, flattenAttr(groupPrefix, child, replace, childGroups)...)
+		}
+		return result
+	}
+
+	if replace != nil {
+		a = replace(groups, a)
+	}
+	if a.Equal(slog.Attr{}) {
+		return nil
+	}
+	if prefix != "" {
+		a.Key = prefix + "." + a.Key
+	}
+	return []slog.Attr{a}
+}
+
+// encodeLogfmt write record as space separated key=value pairs
+func encodeLogfmt(buf *strings.Builder, r encodedRecord) {
+	if !r.time.IsZero() {
+		buf.WriteString("time=")
+		buf.WriteString(r.time.Format(time.RFC3339Nano))
+		buf.WriteByte(' ')
+	}
+	buf.WriteString("level=")
+	buf.WriteString(levelName(r.level))
+	if r.msg != "" {
+		buf.WriteString(" msg=")
+		buf.WriteString(logfmtValue(slog.StringValue(r.msg)))
+	}
+	for _, a := range r.attrs {
+		buf.WriteByte(' ')
+		buf.WriteString(a.Key)
+		buf.WriteByte('=')
+		buf.WriteString(logfmtValue(a.Value))
+	}
+}
+
+// logfmtValue format a value, quoting it when needed. Non scalar value is encoded as JSON.
+func logfmtValue(v slog.Value) string {
+	var s string
+	switch v.Kind() {
+	case slog.KindString:
+		s = v.String()
+	case slog.KindTime:
+		s = v.Time().Format(time.RFC3339Nano)
+	case slog.KindAny:
+		s = anyToString(v.Any())
+	default:
+		s = v.String()
+	}
+	if needsQuoting(s) {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// anyToString encode any value to string, using JSON for complex value
+func anyToString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%+v", value)
+	}
+	return string(data)
+}
+
+func needsQuoting(s string) bool {
+	if s == "" {
+		return true
+	}
+	for _, r := range s {
+		if r == ' ' || r == '=' || r == '"' || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
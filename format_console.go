@@ -0,0 +1,162 @@
+package log
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+)
+
+const (
+	colorReset   = "\033[0m"
+	colorRed     = "\033[31m"
+	colorGreen   = "\033[32m"
+	colorYellow  = "\033[33m"
+	colorBlue    = "\033[34m"
+	colorMagenta = "\033[35m"
+	colorCyan    = "\033[36m"
+	colorGray    = "\033[90m"
+	colorBoldRed = "\033[1;31m"
+
+	consoleTimeFormat = "15:04:05.000"
+)
+
+// encodeConsole write human friendly colored output
+func encodeConsole(buf *strings.Builder, r encodedRecord) {
+	writeConsole(buf, r, true)
+}
+
+// encodePlainConsole write the same layout as encodeConsole without color, used when output is not a terminal
+func encodePlainConsole(buf *strings.Builder, r encodedRecord) {
+	writeConsole(buf, r, false)
+}
+
+// writeConsole render a record as "time LEVEL caller msg key=value" and the sub-logs as an indented tree
+func writeConsole(buf *strings.Builder, r encodedRecord, useColor bool) {
+	paint := func(_, text string) string { return text }
+	if useColor {
+		paint = func(color, text string) string { return color + text + colorReset }
+	}
+
+	var (
+		caller  string
+		message = r.msg
+		attrs   []slog.Attr
+		subLogs []SubLog
+	)
+
+	for _, a := range r.attrs {
+		switch a.Key {
+		case "caller":
+			caller = a.Value.String()
+		case slog.MessageKey:
+			if message == "" {
+				message = a.Value.String()
+			}
+		case "subLog":
+			subLogs, _ = a.Value.Any().([]SubLog)
+		default:
+			attrs = append(attrs, a)
+		}
+	}
+
+	if !r.time.IsZero() {
+		buf.WriteString(paint(colorGray, r.time.Format(consoleTimeFormat)))
+		buf.WriteByte(' ')
+	}
+	buf.WriteString(paint(levelColor(r.level), padRight(levelName(r.level), 7)))
+	if caller != "" {
+		buf.WriteByte(' ')
+		buf.WriteString(paint(colorGray, caller))
+	}
+	if message != "" {
+		buf.WriteByte(' ')
+		buf.WriteString(message)
+	}
+	for _, a := range attrs {
+		buf.WriteByte(' ')
+		buf.WriteString(paint(colorCyan, a.Key+"="))
+		if a.Value.Kind() == slog.KindAny {
+			buf.WriteString(anyToString(a.Value.Any())) // Keep JSON readable without escaping
+		} else {
+			buf.WriteString(logfmtValue(a.Value))
+		}
+	}
+
+	for i, sub := range subLogs {
+		branch := "├─"
+		if i == len(subLogs)-1 {
+			branch = "└─"
+		}
+		buf.WriteString("\n    ")
+		buf.WriteString(paint(colorGray, branch))
+		buf.WriteByte(' ')
+		buf.WriteString(paint(colorGray, fmt.Sprintf("+%.3fms", float64(sub.Offset.Microseconds())/1e3)))
+		buf.WriteByte(' ')
+		buf.WriteString(paint(subLevelColor(sub.Level), padRight(sub.Level, 5)))
+		if sub.Caller != "" {
+			buf.WriteByte(' ')
+			buf.WriteString(paint(colorGray, sub.Caller))
+		}
+		buf.WriteByte(' ')
+		buf.WriteString(strings.ReplaceAll(sub.Message, "\n", "\n       "))
+		for _, key := range sortedKeys(sub.Fields) {
+			buf.WriteByte(' ')
+			buf.WriteString(paint(colorCyan, key+"="))
+			buf.WriteString(anyToString(sub.Fields[key]))
+		}
+	}
+}
+
+func levelColor(level slog.Level) string {
+	switch {
+	case level < LevelInfo:
+		return colorMagenta
+	case level < LevelWarning:
+		return colorBlue
+	case level < LevelError:
+		return colorYellow
+	case level < LevelFatal:
+		return colorRed
+	case level < LevelTrace:
+		return colorBoldRed
+	case level < LevelRequest:
+		return colorCyan
+	default:
+		return colorGreen
+	}
+}
+
+// subLevelColor pick color from the level of a sub-log, custom label like GORM is gray
+func subLevelColor(level string) string {
+	switch level {
+	case subLevelDebug:
+		return colorMagenta
+	case subLevelInfo:
+		return colorBlue
+	case subLevelWarn:
+		return colorYellow
+	case subLevelError:
+		return colorRed
+	case subLevelFatal:
+		return colorBoldRed
+	default:
+		return colorGray
+	}
+}
+
+func padRight(s string, length int) string {
+	if len(s) >= length {
+		return s
+	}
+	return s + strings.Repeat(" ", length-len(s))
+}
+
+func sortedKeys(fields map[string]any) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
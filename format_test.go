@@ -0,0 +1,138 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormats(t *testing.T) {
+	tests := []struct {
+		format   Format
+		contains []string
+	}{
+		{FormatJSON, []string{`"level":"WARN"`, `"caller":"`, `"msg":"user created"`, `"id":10`, `"name":"John Doe"`, `"tags":["a","b"]`}},
+		{FormatLogfmt, []string{"level=WARN", "caller=", `msg="user created"`, "id=10", `name="John Doe"`, `tags="[\"a\",\"b\"]"`}},
+		{FormatText, []string{"level=WARN", "caller=", `msg="user created"`, "id=10", `name="John Doe"`}},
+		{FormatConsole, []string{"WARN   ", "format_test.go:", "user created", "id=10", `name="John Doe"`, `tags=["a","b"]`}},
+		{"unknown", []string{`"level":"WARN"`, `"msg":"user created"`}},
+	}
+	for _, test := range tests {
+		t.Run(string(test.format), func(t *testing.T) {
+			var buf bytes.Buffer
+			logger, err := Config{Format: test.format, CustomWriter: &buf}.Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			logger.Warnw("user created", "id", 10, "name", "John Doe", "tags", []string{"a", "b"})
+
+			output := buf.String()
+			if strings.Count(output, "\n") != 1 {
+				t.Fatalf("expected a single line, got %q", output)
+			}
+			for _, text := range test.contains {
+				if !strings.Contains(output, text) {
+					t.Errorf("expected %q in %q", text, output)
+				}
+			}
+			if test.format == FormatConsole && strings.Contains(output, "\033[") {
+				t.Errorf("expected no color when the output is not a terminal, got %q", output)
+			}
+		})
+	}
+}
+
+func TestLogfmtValue(t *testing.T) {
+	tests := []struct {
+		value    slog.Value
+		expected string
+	}{
+		{slog.StringValue("plain"), "plain"},
+		{slog.StringValue(""), `""`},
+		{slog.StringValue("with space"), `"with space"`},
+		{slog.StringValue(`a="b"`), `"a=\"b\""`},
+		{slog.StringValue("line\nbreak"), `"line\nbreak"`},
+		{slog.IntValue(-5), "-5"},
+		{slog.BoolValue(true), "true"},
+		{slog.TimeValue(time.Date(2021, 10, 22, 0, 0, 0, 0, time.UTC)), "2021-10-22T00:00:00Z"},
+		{slog.AnyValue(nil), "null"},
+		{slog.AnyValue(map[string]int{"a": 1}), `"{\"a\":1}"`},
+		{slog.AnyValue([]int{1, 2}), "[1,2]"},
+	}
+	for _, test := range tests {
+		if value := logfmtValue(test.value); value != test.expected {
+			t.Errorf("expected %s, got %s", test.expected, value)
+		}
+	}
+}
+
+func TestLogfmtGroups(t *testing.T) {
+	var buf bytes.Buffer
+	handler := newEncoderHandler(&buf, &slog.HandlerOptions{ReplaceAttr: replaceAttr}, encodeLogfmt)
+	logger := slog.New(handler).With("service", "payment").WithGroup("http")
+
+	logger.Info("request", "method", "GET", slog.Group("user", "id", 10), slog.Group("", "inline", true))
+
+	output := buf.String()
+	for _, text := range []string{"service=payment", "http.method=GET", "http.user.id=10", "http.inline=true"} {
+		if !strings.Contains(output, text) {
+			t.Errorf("expected %q in %q", text, output)
+		}
+	}
+}
+
+func TestConsoleSubLogs(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoder encodeFunc
+		color   bool
+	}{
+		{"plain", encodePlainConsole, false},
+		{"color", encodeConsole, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			handler := newEncoderHandler(&buf, &slog.HandlerOptions{ReplaceAttr: replaceAttr}, test.encoder)
+
+			record := slog.NewRecord(time.Date(2021, 10, 22, 8, 30, 0, 0, time.UTC), LevelRequest, "", 0)
+			record.AddAttrs(slog.String("caller", "middleware/echo.go:40"), slog.Any("subLog", []SubLog{
+				{Offset: 1500 * time.Microsecond, Level: subLevelInfo, Caller: "usecase/user.go:20", Message: "find user", Fields: map[string]any{"id": 10}},
+				{Offset: 3 * time.Millisecond, Level: "GORM", Message: "SELECT 1"},
+			}))
+			if err := handler.Handle(context.Background(), record); err != nil {
+				t.Fatal(err)
+			}
+
+			output := buf.String()
+			if test.color != strings.Contains(output, colorGreen) {
+				t.Fatalf("expected color %v, got %q", test.color, output)
+			}
+			plain := output
+			for _, color := range []string{colorReset, colorGreen, colorGray, colorBlue, colorCyan} {
+				plain = strings.ReplaceAll(plain, color, "")
+			}
+			expected := "08:30:00.000 REQUEST middleware/echo.go:40\n" +
+				"    ├─ +1.500ms INFO  usecase/user.go:20 find user id=10\n" +
+				"    └─ +3.000ms GORM  SELECT 1\n"
+			if plain != expected {
+				t.Fatalf("expected\n%s\ngot\n%s", expected, plain)
+			}
+		})
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, value := range []string{"json", "logfmt", "text", "console", "JSON"} {
+		if _, err := parseFormat(value); err != nil {
+			t.Errorf("expected %q accepted, got %v", value, err)
+		}
+	}
+	if _, err := parseFormat("xml"); err == nil {
+		t.Error("expected unknown format rejected")
+	}
+}
@@ -0,0 +1,128 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+
+	"go.uber.org/zap/zapcore"
+)
+
+type (
+	// ContextHandler is a slog.Handler which route slog calls into the request sub-logs.
+	// When the context has a request saved by SaveToContext the record is appended to its sub-logs,
+	// otherwise it is written as a global log. Use it with slog.SetDefault for a single log pipeline.
+	ContextHandler struct {
+		logger *Logger        // Nil use the default logger at the time of logging
+		groups []handlerGroup // First element is the root without group name
+	}
+
+	// handlerGroup hold attributes added by WithAttrs inside a group
+	handlerGroup struct {
+		name  string
+		attrs []slog.Attr
+	}
+)
+
+// NewContextHandler create slog handler for the logger, nil logger use the default logger
+func NewContextHandler(l *Logger) *ContextHandler {
+	return &ContextHandler{logger: l, groups: []handlerGroup{{}}}
+}
+
+// SlogHandler return slog handler which route slog calls into this logger
+func (l *Logger) SlogHandler() *ContextHandler {
+	return NewContextHandler(l)
+}
+
+func (h *ContextHandler) getLogger() *Logger {
+	if h.logger != nil {
+		return h.logger
+	}
+	return Default()
+}
+
+func (h *ContextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	logger := h.getLogger()
+	if m, ok := ctx.Value(logRequestKey).(*request); ok && !m.logger.disableSubLogs {
+		return true // Sub-log level is checked after the caller is known
+	}
+	return level >= logger.handlerLevel.Level()
+}
+
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	clone := h.clone()
+	last := &clone.groups[len(clone.groups)-1]
+	last.attrs = append(append([]slog.Attr{}, last.attrs...), attrs...)
+	return clone
+}
+
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	clone := h.clone()
+	clone.groups = append(clone.groups, handlerGroup{name: name})
+	return clone
+}
+
+func (h *ContextHandler) clone() *ContextHandler {
+	return &ContextHandler{logger: h.logger, groups: append([]handlerGroup{}, h.groups...)}
+}
+
+func (h *ContextHandler) Handle(ctx context.Context, r slog.Record) error {
+	caller := recordCaller(r)
+	fields := append(append([]slog.Attr{}, fieldsFromContext(ctx)...), h.fields(r)...)
+
+	if m, ok := ctx.Value(logRequestKey).(*request); ok {
+		m.record(r.Level, caller, r.Message, fields)
+		return nil
+	}
+
+	logger := h.getLogger()
+	if !logger.enabled(r.Level, caller.File) || !logger.sampler.allowGlobal(r.Level, r.Message) {
+		return nil
+	}
+
+	logger.write(ctx, &Entry{Time: r.Time, Level: r.Level, Caller: callerPath(caller), Message: r.Message, Fields: fields, noTime: r.Time.IsZero()})
+	return nil
+}
+
+// fields nest the handler and record attributes inside their groups
+func (h *ContextHandler) fields(r slog.Record) []slog.Attr {
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+
+	for i := len(h.groups) - 1; i >= 0; i-- {
+		group := h.groups[i]
+		attrs = append(append([]slog.Attr{}, group.attrs...), attrs...)
+		if group.name != "" && len(attrs) > 0 {
+			attrs = []slog.Attr{{Key: group.name, Value: slog.GroupValue(attrs...)}}
+		}
+	}
+	return attrs
+}
+
+// recordCaller find the file and line of the slog call, the caller is undefined when the record has no PC
+func recordCaller(r slog.Record) zapcore.EntryCaller {
+	if r.PC == 0 {
+		return zapcore.EntryCaller{}
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+	return zapcore.NewEntryCaller(frame.PC, frame.File, frame.Line, frame.File != "")
+}
+
+// callerPath return the trimmed path of caller, empty when it is undefined so the caller is left out
+func callerPath(caller zapcore.EntryCaller) string {
+	if !caller.Defined {
+		return ""
+	}
+	return caller.TrimmedPath()
+}
@@ -0,0 +1,99 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+	"testing/slogtest"
+	"time"
+)
+
+func TestContextHandlerSlogtest(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newTestLogger(t, &buf, Config{})
+
+	results := func() []map[string]any {
+		var entries []map[string]any
+		for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+			var entry map[string]any
+			if err := json.Unmarshal(line, &entry); err != nil {
+				t.Fatalf("invalid JSON line %q, %v", line, err)
+			}
+			entries = append(entries, entry)
+		}
+		return entries
+	}
+
+	if err := slogtest.TestHandler(logger.SlogHandler(), results); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestContextHandlerRecordWithoutTimeAndCaller(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		absent []string
+	}{
+		{"json", FormatJSON, []string{`"time"`, `"caller"`, "undefined"}},
+		{"logfmt", FormatLogfmt, []string{"time=", "caller=", "undefined"}},
+		{"text", FormatText, []string{"time=", "caller=", "undefined"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger, err := Config{Format: test.format, CustomWriter: &buf}.Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			record := slog.NewRecord(time.Time{}, LevelInfo, "no time", 0)
+			if err := logger.SlogHandler().Handle(context.Background(), record); err != nil {
+				t.Fatal(err)
+			}
+
+			output := buf.String()
+			if !strings.Contains(output, "no time") {
+				t.Fatalf("expected the record written, got %q", output)
+			}
+			for _, absent := range test.absent {
+				if strings.Contains(output, absent) {
+					t.Errorf("expected %s left out, got %q", absent, output)
+				}
+			}
+		})
+	}
+}
+
+func TestContextHandlerRouteToSubLogs(t *testing.T) {
+	logger, recorder := newRecordingLogger(t, Config{})
+	slogger := slog.New(logger.SlogHandler()).With("service", "payment").WithGroup("order")
+
+	req := logger.NewRequest()
+	ctx := req.SaveToContext(context.Background())
+	slogger.InfoContext(ctx, "inside request", "id", 10)
+	slogger.Info("outside request")
+	req.Save()
+
+	requests := recorder.requests(t, logger)
+	if len(requests) != 1 || len(requests[0].SubLogs) != 1 {
+		t.Fatalf("expected 1 REQUEST entry with 1 sub-log, got %+v", requests)
+	}
+	subLog := requests[0].SubLogs[0]
+	if subLog.Message != "inside request" || !strings.Contains(subLog.Caller, "handler_test.go:") {
+		t.Fatalf("expected sub-log with the slog caller, got %+v", subLog)
+	}
+	if subLog.Fields["service"] != "payment" || fmt.Sprint(subLog.Fields["order"]) != "map[id:10]" {
+		t.Fatalf("expected attributes nested in the group, got %v", subLog.Fields)
+	}
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	if entry := recorder.entries[0]; entry.Message != "outside request" || entry.Request != nil {
+		t.Fatalf("expected record without request written as global log, got %+v", entry)
+	}
+}
@@ -1,10 +1,12 @@
 package log
 
 import (
+	"bytes"
 	"fmt"
 	"math/rand"
 	"reflect"
 	"runtime"
+	"strconv"
 	"strings"
 
 	"go.uber.org/zap/zapcore"
@@ -44,6 +46,18 @@ func GetCaller(level string, skip int) string {
 	return fmt.Sprintf("[%s] %s", level, entryCaller.TrimmedPath())
 }
 
+// goroutineID parse the id of the current goroutine from the stack header, example "goroutine 18 [running]:"
+func goroutineID() uint64 {
+	var buf [32]byte
+	header := buf[:runtime.Stack(buf[:], false)]
+	header = bytes.TrimPrefix(header, []byte("goroutine "))
+	if index := bytes.IndexByte(header, ' '); index > 0 {
+		header = header[:index]
+	}
+	id, _ := strconv.ParseUint(string(header), 10, 64)
+	return id
+}
+
 func maskSensitiveData(payload any) {
 	// Check if req is a pointer to a struct
 	v := reflect.ValueOf(payload)
@@ -3,7 +3,6 @@ package log
 import (
 	"context"
 	"encoding/json"
-	"log/slog"
 	"net/http"
 	"time"
 )
@@ -36,6 +35,12 @@ func NewTrace(method, url string, reqHeader, reqBody any, addToExtraData bool) t
 }
 
 func (t *trace) Save(ctx context.Context, resp *http.Response) {
+	requestLog := Context(ctx)
+	if !t.addToExtraData && !requestLog.logger.TraceEnabled() {
+		return // Skip decoding the response body
+	}
+	defer requestLog.logger.trackSave()()
+
 	if err := json.Unmarshal(t.RawRespBody, &t.RespBody); err != nil {
 		t.RespBody = string(t.RawRespBody)
 	}
@@ -49,20 +54,23 @@ func (t *trace) Save(ctx context.Context, resp *http.Response) {
 	t.Duration = time.Since(t.Time).Milliseconds()
 
 	if t.addToExtraData {
-		Context(ctx).ExtraData[t.Url] = t
+		requestLog.SetExtra(t.Url, t)
 		return
 	}
 
-	globalLogger.LogAttrs(ctx, LevelTrace, "",
-		slog.String("caller", GetCaller("", 4)),
-		slog.String(traceID, Context(ctx).TraceID()),
-		slog.String("method", t.Method),
-		slog.String("url", t.Url),
-		slog.Int("statusCode", t.StatusCode),
-		slog.Int64("totalDuration", t.Duration),
-		slog.Any("requestHeader", t.ReqHeader),
-		slog.Any("requestBody", t.ReqBody),
-		slog.Any("responseHeader", t.RespHeader),
-		slog.Any("responseBody", t.RespBody),
-	)
+	requestLog.logger.write(ctx, &Entry{
+		Level:   LevelTrace,
+		Caller:  GetCaller("", 4),
+		TraceID: requestLog.TraceID(),
+		Trace: &TraceData{
+			Method:         t.Method,
+			URL:            t.Url,
+			StatusCode:     t.StatusCode,
+			Duration:       time.Duration(t.Duration) * time.Millisecond,
+			RequestHeader:  t.ReqHeader,
+			RequestBody:    t.ReqBody,
+			ResponseHeader: t.RespHeader,
+			ResponseBody:   t.RespBody,
+		},
+	})
 }
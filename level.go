@@ -0,0 +1,189 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// levelSteps is the order used when stepping the level up or down at runtime
+var levelSteps = []slog.Level{LevelDebug, LevelInfo, LevelWarning, LevelError, LevelFatal}
+
+// ParseLevel convert level name like "debug", "warn" or "request" into slog.Level.
+// Numeric value is also accepted.
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToUpper(strings.TrimSpace(name)) {
+	case "DEBUG":
+		return LevelDebug, nil
+	case "INFO":
+		return LevelInfo, nil
+	case "WARN", "WARNING":
+		return LevelWarning, nil
+	case "ERROR":
+		return LevelError, nil
+	case "FATAL":
+		return LevelFatal, nil
+	case "TRACE":
+		return LevelTrace, nil
+	case "REQUEST":
+		return LevelRequest, nil
+	}
+
+	if number, err := strconv.Atoi(strings.TrimSpace(name)); err == nil {
+		return slog.Level(number), nil
+	}
+
+	return 0, fmt.Errorf("unknown log level %q", name)
+}
+
+// Level return the current log level
+func (l *Logger) Level() slog.Level {
+	return l.level.Level()
+}
+
+// SetLevel change the log level while the process is running, cancelling any pending auto revert
+func (l *Logger) SetLevel(level slog.Level) {
+	l.levelMu.Lock()
+	defer l.levelMu.Unlock()
+
+	l.stopLevelRevert()
+	l.applyLevel(level)
+}
+
+// SetLevelFor change the log level and revert to the previous level after ttl
+func (l *Logger) SetLevelFor(level slog.Level, ttl time.Duration) {
+	l.levelMu.Lock()
+	defer l.levelMu.Unlock()
+
+	l.stopLevelRevert()
+	previous := l.level.Level()
+	l.applyLevel(level)
+
+	var timer *time.Timer
+	timer = time.AfterFunc(ttl, func() {
+		l.levelMu.Lock()
+		defer l.levelMu.Unlock()
+
+		// Skip if level was changed again after this timer started
+		if l.levelRevert == timer {
+			l.applyLevel(previous)
+			l.levelRevert = nil
+		}
+	})
+	l.levelRevert = timer
+}
+
+// stepLevel move the level by delta steps, negative delta is more verbose
+func (l *Logger) stepLevel(delta int) slog.Level {
+	l.levelMu.Lock()
+	defer l.levelMu.Unlock()
+
+	current := l.level.Level()
+	index := len(levelSteps) - 1
+	for i, level := range levelSteps {
+		if level >= current {
+			index = i
+			break
+		}
+	}
+
+	index = min(max(index+delta, 0), len(levelSteps)-1)
+
+	l.stopLevelRevert()
+	l.applyLevel(levelSteps[index])
+	return levelSteps[index]
+}
+
+func (l *Logger) stopLevelRevert() {
+	if l.levelRevert != nil {
+		l.levelRevert.Stop()
+		l.levelRevert = nil
+	}
+}
+
+// LevelHandler return admin http handler for reading and changing the log level.
+//
+//	GET  return the current level, example {"level":"INFO"}
+//	PUT  set a new level, example {"level":"DEBUG","ttl":"10m"} or ?level=debug&ttl=10m
+//
+// When ttl is set the level will be reverted to the previous level after the duration.
+func (l *Logger) LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			writeJSONResponse(w, http.StatusOK, map[string]string{"level": levelName(l.Level())})
+
+		case http.MethodPut:
+			payload := struct {
+				Level string `json:"level"`
+				TTL   string `json:"ttl"`
+			}{
+				Level: r.URL.Query().Get("level"),
+				TTL:   r.URL.Query().Get("ttl"),
+			}
+
+			if payload.Level == "" {
+				if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+					writeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid request body, " + err.Error()})
+					return
+				}
+			}
+
+			level, err := ParseLevel(payload.Level)
+			if err != nil {
+				writeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+				return
+			}
+
+			response := map[string]string{"level": levelName(level)}
+
+			if payload.TTL == "" {
+				l.SetLevel(level)
+			} else {
+				ttl, err := time.ParseDuration(payload.TTL)
+				if err != nil || ttl <= 0 {
+					writeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("invalid ttl %q", payload.TTL)})
+					return
+				}
+				l.SetLevelFor(level, ttl)
+				response["revertAt"] = time.Now().Add(ttl).Format(time.RFC3339)
+			}
+
+			writeJSONResponse(w, http.StatusOK, response)
+
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			writeJSONResponse(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		}
+	})
+}
+
+func writeJSONResponse(w http.ResponseWriter, statusCode int, body map[string]string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(body)
+}
+
+// GetLevel return the current level of the default logger
+func GetLevel() slog.Level {
+	return Default().Level()
+}
+
+// SetLevel change the level of the default logger while the process is running
+func SetLevel(level slog.Level) {
+	Default().SetLevel(level)
+}
+
+// LevelHandler return admin http handler for the default logger level
+func LevelHandler() http.Handler {
+	return Default().LevelHandler()
+}
+
+// HandleLevelSignals step the default logger level with SIGUSR1 (more verbose) and SIGUSR2 (less verbose)
+func HandleLevelSignals() (stop func()) {
+	return Default().HandleLevelSignals()
+}
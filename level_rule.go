@@ -0,0 +1,182 @@
+package log
+
+import (
+	"fmt"
+	"log/slog"
+	"path"
+	"sort"
+	"strings"
+)
+
+type (
+	// levelRule override the log level for callers inside a package path
+	levelRule struct {
+		pattern   string // Original pattern, example "repository/*"
+		base      string // Pattern without wildcard suffix
+		recursive bool   // Pattern end with "/*", match sub packages too
+		file      bool   // Pattern end with ".go", match a single file
+		level     slog.Level
+	}
+
+	// levelRules is sorted from the most specific pattern
+	levelRules []levelRule
+)
+
+// ParseLevelRules parse comma separated rules, example "repository/*=WARN,usecase/payment=DEBUG"
+func ParseLevelRules(rules string) (map[string]slog.Level, error) {
+	result := make(map[string]slog.Level)
+	for _, rule := range strings.Split(rules, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		pattern, levelName, found := strings.Cut(rule, "=")
+		if !found || strings.TrimSpace(pattern) == "" {
+			return nil, fmt.Errorf("invalid level rule %q, expected format package/path=LEVEL", rule)
+		}
+
+		level, err := ParseLevel(levelName)
+		if err != nil {
+			return nil, fmt.Errorf("invalid level rule %q, %w", rule, err)
+		}
+
+		result[strings.TrimSpace(pattern)] = level
+	}
+	return result, nil
+}
+
+func newLevelRules(rules map[string]slog.Level) levelRules {
+	var result levelRules
+	for pattern, level := range rules {
+		pattern = strings.Trim(strings.TrimSpace(pattern), "/")
+		if pattern == "" {
+			continue
+		}
+
+		rule := levelRule{pattern: pattern, base: pattern, level: level}
+		switch {
+		case pattern == "*":
+			rule.base, rule.recursive = "", true
+		case strings.HasSuffix(pattern, "/*"):
+			rule.base, rule.recursive = strings.TrimSuffix(pattern, "/*"), true
+		case strings.HasSuffix(pattern, ".go"):
+			rule.file = true
+		}
+		result = append(result, rule)
+	}
+
+	// Most specific pattern win, non recursive win over recursive with the same base
+	sort.Slice(result, func(i, j int) bool {
+		if len(result[i].base) != len(result[j].base) {
+			return len(result[i].base) > len(result[j].base)
+		}
+		return !result[i].recursive && result[j].recursive
+	})
+	return result
+}
+
+// match return the level of the first rule matching the caller file path
+func (rules levelRules) match(file string) (slog.Level, bool) {
+	if len(rules) == 0 || file == "" {
+		return 0, false
+	}
+
+	dir := path.Dir(file)
+	for _, rule := range rules {
+		switch {
+		case rule.file:
+			if file == rule.base || strings.HasSuffix(file, "/"+rule.base) {
+				return rule.level, true
+			}
+		case rule.recursive:
+			if rule.base == "" || strings.Contains("/"+dir+"/", "/"+rule.base+"/") {
+				return rule.level, true
+			}
+		default:
+			if dir == rule.base || strings.HasSuffix(dir, "/"+rule.base) {
+				return rule.level, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// minLevel return the lowest level of all rules
+func (rules levelRules) minLevel(level slog.Level) slog.Level {
+	for _, rule := range rules {
+		level = min(level, rule.level)
+	}
+	return level
+}
+
+// SetLevelRules replace the per package level overrides at runtime
+func (l *Logger) SetLevelRules(rules map[string]slog.Level) {
+	l.levelMu.Lock()
+	defer l.levelMu.Unlock()
+
+	parsed := newLevelRules(rules)
+	l.levelRules.Store(&parsed)
+	l.applyLevel(l.level.Level())
+}
+
+// LevelRules return the current per package level overrides
+func (l *Logger) LevelRules() map[string]slog.Level {
+	result := make(map[string]slog.Level)
+	for _, rule := range l.rules() {
+		result[rule.pattern] = rule.level
+	}
+	return result
+}
+
+func (l *Logger) rules() levelRules {
+	if rules := l.levelRules.Load(); rules != nil {
+		return *rules
+	}
+	return nil
+}
+
+// enabled report whether a log with the level from the caller file should be printed
+func (l *Logger) enabled(level slog.Level, file string) bool {
+	if ruleLevel, ok := l.rules().match(file); ok {
+		return level >= ruleLevel
+	}
+	return level >= l.level.Level()
+}
+
+// subLogEnabled report whether a sub-log with the level from the caller file should be recorded
+func (l *Logger) subLogEnabled(level slog.Level, file string) bool {
+	if ruleLevel, ok := l.rules().match(file); ok {
+		return level >= ruleLevel
+	}
+	return level >= l.subLogLevelOrDefault()
+}
+
+// subLogLevelOrDefault return Config.SubLogLevel, or the current log level when it is not set
+func (l *Logger) subLogLevelOrDefault() slog.Level {
+	if l.subLogLevel != nil {
+		return *l.subLogLevel
+	}
+	return l.level.Level()
+}
+
+// subLogMinLevel return the lowest level a request log could record, including the level rules.
+// Lower level is skipped before formatting the message and finding the caller.
+func (l *Logger) subLogMinLevel() slog.Level {
+	if l.disableSubLogs {
+		return l.handlerLevel.Level()
+	}
+	return l.rules().minLevel(l.subLogLevelOrDefault())
+}
+
+// applyLevel set the configured level and lower the handler level when a rule need a more verbose level.
+// Caller must hold levelMu.
+func (l *Logger) applyLevel(level slog.Level) {
+	l.level.Set(level)
+	l.handlerLevel.Set(l.rules().minLevel(level))
+}
+
+// SetLevelRules replace the per package level overrides of the default logger
+func SetLevelRules(rules map[string]slog.Level) {
+	Default().SetLevelRules(rules)
+}
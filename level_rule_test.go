@@ -0,0 +1,107 @@
+package log
+
+import (
+	"bytes"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLevelRulesMatch(t *testing.T) {
+	rules := newLevelRules(map[string]slog.Level{
+		"repository/*":      LevelWarning,
+		"repository/cache":  LevelDebug,
+		"usecase/payment":   LevelDebug,
+		"usecase/user.go":   LevelError,
+		"/handler/":         LevelInfo,
+		"internal/*":        LevelError,
+		"internal/report/*": LevelInfo,
+	})
+
+	tests := []struct {
+		file     string
+		expected slog.Level
+		found    bool
+	}{
+		{"/app/repository/user.go", LevelWarning, true},
+		{"/app/repository/mysql/user.go", LevelWarning, true},
+		{"/app/repository/cache/redis.go", LevelDebug, true},
+		{"/app/repository/cache/sub/redis.go", LevelWarning, true},
+		{"/app/usecase/payment/charge.go", LevelDebug, true},
+		{"/app/usecase/payment/refund/refund.go", 0, false},
+		{"/app/usecase/user.go", LevelError, true},
+		{"/app/usecase/other_user.go", 0, false},
+		{"/app/handler/http.go", LevelInfo, true},
+		{"/app/internal/report/daily/job.go", LevelInfo, true},
+		{"/app/internal/auth/jwt.go", LevelError, true},
+		{"/app/myrepository/user.go", 0, false},
+		{"", 0, false},
+	}
+	for _, test := range tests {
+		level, found := rules.match(test.file)
+		if found != test.found || level != test.expected {
+			t.Errorf("%q: expected %v %v, got %v %v", test.file, test.expected, test.found, level, found)
+		}
+	}
+
+	if level, found := newLevelRules(map[string]slog.Level{"*": LevelError}).match("/app/main.go"); !found || level != LevelError {
+		t.Errorf("expected * to match every file, got %v %v", level, found)
+	}
+}
+
+func TestParseLevelRules(t *testing.T) {
+	tests := []struct {
+		rules    string
+		expected map[string]slog.Level
+		invalid  bool
+	}{
+		{"repository/*=WARN, usecase/payment=debug,", map[string]slog.Level{"repository/*": LevelWarning, "usecase/payment": LevelDebug}, false},
+		{"", map[string]slog.Level{}, false},
+		{"repository/*", nil, true},
+		{"=WARN", nil, true},
+		{"repository/*=verbose", nil, true},
+	}
+	for _, test := range tests {
+		rules, err := ParseLevelRules(test.rules)
+		if test.invalid {
+			if err == nil {
+				t.Errorf("%q: expected error", test.rules)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(rules, test.expected) {
+			t.Errorf("%q: expected %v, got %v %v", test.rules, test.expected, rules, err)
+		}
+	}
+}
+
+func TestLoggerLevelRules(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newTestLogger(t, &buf, Config{Level: LevelWarning, LevelRules: map[string]slog.Level{"level_rule_test.go": LevelDebug}})
+	if logger.handlerLevel.Level() != LevelDebug {
+		t.Fatalf("expected handler level lowered to DEBUG by the rule, got %v", logger.handlerLevel.Level())
+	}
+
+	logger.Debug("debug from rule")
+	if !strings.Contains(buf.String(), "debug from rule") {
+		t.Fatalf("expected rule to enable DEBUG for this file, got %q", buf.String())
+	}
+	if logger.enabled(LevelInfo, "/app/other/main.go") {
+		t.Fatal("expected other files to follow Config.Level")
+	}
+
+	// Replace the rules at runtime
+	logger.SetLevelRules(map[string]slog.Level{"repository/*": LevelError})
+	buf.Reset()
+	logger.Debug("debug without rule")
+	if buf.Len() != 0 {
+		t.Fatalf("expected DEBUG skipped after the rule is removed, got %q", buf.String())
+	}
+	if logger.handlerLevel.Level() != LevelWarning {
+		t.Fatalf("expected handler level back to WARN, got %v", logger.handlerLevel.Level())
+	}
+	if rules := logger.LevelRules(); !reflect.DeepEqual(rules, map[string]slog.Level{"repository/*": LevelError}) {
+		t.Fatalf("expected current rules returned, got %v", rules)
+	}
+}
@@ -0,0 +1,42 @@
+//go:build !windows
+
+package log
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// HandleLevelSignals step the level with SIGUSR1 (more verbose) and SIGUSR2 (less verbose).
+// Call stop to stop listening the signals.
+func (l *Logger) HandleLevelSignals() (stop func()) {
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(signals, syscall.SIGUSR1, syscall.SIGUSR2)
+
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				delta := 1
+				if sig == syscall.SIGUSR1 {
+					delta = -1
+				}
+				level := l.stepLevel(delta)
+				l.logWithCaller(LevelWarning, "log level changed to "+levelName(level)+" by signal "+sig.String(), 1)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(signals)
+			close(done)
+		})
+	}
+}
@@ -0,0 +1,41 @@
+//go:build !windows
+
+package log
+
+import (
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestHandleLevelSignals(t *testing.T) {
+	var buf syncWriter
+	buf.w = &strings.Builder{}
+	logger := newTestLogger(t, &buf, Config{Level: LevelWarning})
+	stop := logger.HandleLevelSignals()
+	defer stop()
+
+	waitLevel := func(expected string) {
+		t.Helper()
+		for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+			if levelName(logger.Level()) == expected {
+				return
+			}
+		}
+		t.Fatalf("expected level %s, got %s", expected, levelName(logger.Level()))
+	}
+
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	waitLevel("INFO")
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR2)
+	waitLevel("WARN")
+
+	stop()
+	stop() // Calling stop twice is safe
+	buf.mu.Lock()
+	defer buf.mu.Unlock()
+	if output := buf.w.(*strings.Builder).String(); !strings.Contains(output, "log level changed to INFO by signal user defined signal 1") {
+		t.Fatalf("expected the level change logged, got %q", output)
+	}
+}
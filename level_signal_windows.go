@@ -0,0 +1,8 @@
+//go:build windows
+
+package log
+
+// HandleLevelSignals is not supported on windows because SIGUSR1 and SIGUSR2 do not exist.
+func (l *Logger) HandleLevelSignals() (stop func()) {
+	return func() {}
+}
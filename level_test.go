@@ -0,0 +1,133 @@
+package log
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected slog.Level
+		invalid  bool
+	}{
+		{"debug", LevelDebug, false},
+		{" INFO ", LevelInfo, false},
+		{"warn", LevelWarning, false},
+		{"warning", LevelWarning, false},
+		{"Error", LevelError, false},
+		{"fatal", LevelFatal, false},
+		{"trace", LevelTrace, false},
+		{"request", LevelRequest, false},
+		{"-8", slog.Level(-8), false},
+		{"verbose", 0, true},
+	}
+	for _, test := range tests {
+		level, err := ParseLevel(test.name)
+		if test.invalid {
+			if err == nil {
+				t.Errorf("%q: expected error", test.name)
+			}
+			continue
+		}
+		if err != nil || level != test.expected {
+			t.Errorf("%q: expected %v, got %v %v", test.name, test.expected, level, err)
+		}
+	}
+}
+
+func TestSetLevelFor(t *testing.T) {
+	logger := newTestLogger(t, &strings.Builder{}, Config{Level: LevelWarning})
+
+	logger.SetLevelFor(LevelDebug, 20*time.Millisecond)
+	if logger.Level() != LevelDebug {
+		t.Fatalf("expected temporary level DEBUG, got %v", logger.Level())
+	}
+	time.Sleep(50 * time.Millisecond)
+	if logger.Level() != LevelWarning {
+		t.Fatalf("expected level reverted to WARN, got %v", logger.Level())
+	}
+
+	// SetLevel cancel the pending revert
+	logger.SetLevelFor(LevelDebug, 20*time.Millisecond)
+	logger.SetLevel(LevelError)
+	time.Sleep(50 * time.Millisecond)
+	if logger.Level() != LevelError {
+		t.Fatalf("expected SetLevel to cancel the revert, got %v", logger.Level())
+	}
+}
+
+func TestStepLevel(t *testing.T) {
+	tests := []struct {
+		name     string
+		start    slog.Level
+		delta    int
+		expected slog.Level
+	}{
+		{"more verbose", LevelWarning, -1, LevelInfo},
+		{"less verbose", LevelWarning, 1, LevelError},
+		{"stop at debug", LevelDebug, -1, LevelDebug},
+		{"stop at fatal", LevelFatal, 1, LevelFatal},
+		{"custom level round up", slog.Level(2), -1, LevelInfo},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			logger := newTestLogger(t, &strings.Builder{}, Config{Level: test.start, LevelSet: true})
+			if level := logger.stepLevel(test.delta); level != test.expected || logger.Level() != test.expected {
+				t.Fatalf("expected %v, got %v", test.expected, level)
+			}
+		})
+	}
+}
+
+func TestLevelHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		statusCode int
+		level      slog.Level
+		revert     bool
+	}{
+		{"get", http.MethodGet, "/log/level", "", http.StatusOK, LevelInfo, false},
+		{"put query", http.MethodPut, "/log/level?level=debug", "", http.StatusOK, LevelDebug, false},
+		{"put body with ttl", http.MethodPut, "/log/level", `{"level":"error","ttl":"10m"}`, http.StatusOK, LevelError, true},
+		{"unknown level", http.MethodPut, "/log/level?level=verbose", "", http.StatusBadRequest, LevelInfo, false},
+		{"invalid ttl", http.MethodPut, "/log/level?level=debug&ttl=-1s", "", http.StatusBadRequest, LevelInfo, false},
+		{"invalid body", http.MethodPut, "/log/level", "{", http.StatusBadRequest, LevelInfo, false},
+		{"method not allowed", http.MethodPost, "/log/level", "", http.StatusMethodNotAllowed, LevelInfo, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			logger := newTestLogger(t, &strings.Builder{}, Config{Level: LevelInfo, LevelSet: true})
+			t.Cleanup(func() { logger.SetLevel(LevelInfo) }) // Stop pending revert
+
+			recorder := httptest.NewRecorder()
+			logger.LevelHandler().ServeHTTP(recorder, httptest.NewRequest(test.method, test.target, strings.NewReader(test.body)))
+
+			if recorder.Code != test.statusCode {
+				t.Fatalf("expected status %d, got %d: %s", test.statusCode, recorder.Code, recorder.Body.String())
+			}
+			if logger.Level() != test.level {
+				t.Fatalf("expected level %v, got %v", test.level, logger.Level())
+			}
+
+			var body map[string]string
+			if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+				t.Fatalf("expected JSON body, %v", err)
+			}
+			if recorder.Code == http.StatusOK && body["level"] != levelName(test.level) {
+				t.Fatalf("expected level %s in body, got %v", levelName(test.level), body)
+			}
+			if _, found := body["revertAt"]; found != test.revert {
+				t.Fatalf("expected revertAt %v, got %v", test.revert, body)
+			}
+		})
+	}
+}
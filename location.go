@@ -0,0 +1,65 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultDirMode  os.FileMode = 0o755
+	defaultFileMode os.FileMode = 0o644
+
+	// legacyLocation is the default Location, always relative to the working directory for backward compatibility
+	legacyLocation = "/log/"
+)
+
+// expandPath replace "~", environment variables and the {hostname}, {pid} and {service} placeholders in path
+func expandPath(path, service string) (string, error) {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed find home directory, %w", err)
+		}
+		path = home + path[1:]
+	}
+
+	path = os.ExpandEnv(path)
+
+	if strings.Contains(path, "{hostname}") {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return "", fmt.Errorf("failed find hostname, %w", err)
+		}
+		path = strings.ReplaceAll(path, "{hostname}", hostname)
+	}
+
+	if service == "" {
+		service = filepath.Base(os.Args[0])
+	}
+	return strings.NewReplacer(
+		"{pid}", strconv.Itoa(os.Getpid()),
+		"{service}", service,
+	).Replace(path), nil
+}
+
+// resolveLocation return the absolute directory of the file log. An absolute location after expansion is used as is,
+// a relative one is joined with the working directory. The legacy default "/log/" stay inside the working directory.
+func resolveLocation(location, service string) (string, error) {
+	relative := location == legacyLocation
+	location, err := expandPath(location, service)
+	if err != nil {
+		return "", err
+	}
+
+	if relative || !filepath.IsAbs(location) {
+		currentDirectory, err := os.Getwd()
+		if err != nil {
+			return "", fmt.Errorf("failed find current Directory for setup Log file, %w", err)
+		}
+		location = filepath.Join(currentDirectory, location)
+	}
+	return filepath.Clean(location) + string(filepath.Separator), nil
+}
@@ -0,0 +1,81 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveLocation(t *testing.T) {
+	workingDirectory, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("LOG_TEST_DIR", "/var/log/env")
+	t.Setenv("LOG_TEST_REL", "logs")
+
+	tests := []struct {
+		name     string
+		location string
+		expected string
+	}{
+		{"legacy default stay relative", "/log/", filepath.Join(workingDirectory, "log") + "/"},
+		{"relative", "log/", filepath.Join(workingDirectory, "log") + "/"},
+		{"absolute", "/var/log/app", "/var/log/app/"},
+		{"home is absolute", "~/log", filepath.Join(home, "log") + "/"},
+		{"absolute environment", "$LOG_TEST_DIR/{service}", "/var/log/env/payment/"},
+		{"relative environment", "$LOG_TEST_REL/{service}", filepath.Join(workingDirectory, "logs/payment") + "/"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			location, err := resolveLocation(test.location, "payment")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if location != test.expected {
+				t.Fatalf("expected %q, got %q", test.expected, location)
+			}
+		})
+	}
+}
+
+func TestBuildFileLogWithDefaultLocation(t *testing.T) {
+	previous, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(previous) })
+
+	logger, err := Config{LogToFile: true}.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	logger.Info("default location")
+	if _, err := os.Stat(filepath.Join("log", "server_log.log")); err != nil {
+		t.Fatalf("expected file log inside the working directory, %v", err)
+	}
+}
+
+func TestBuildFileLogWithAbsoluteLocation(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "{service}")
+
+	logger, err := Config{LogToFile: true, Location: dir, ServiceName: "payment"}.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	logger.Info("absolute location")
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dir), "payment", "server_log.log")); err != nil {
+		t.Fatalf("expected file log inside the absolute location, %v", err)
+	}
+}
@@ -4,16 +4,19 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"log"
 	"log/slog"
 	"os"
+	"runtime"
+	"sync"
+	"sync/atomic"
 	"time"
 
-	rotatelogs "github.com/lestrrat-go/file-rotatelogs"
+	"go.uber.org/zap/zapcore"
 )
 
 const (
-	traceID = "traceID"
+	traceID         = "traceID"
+	globalSkipLevel = 2 // logWithCaller -> Debug/Info/... -> caller
 
 	// Logging level from least important to most important
 	LevelDebug   = slog.LevelDebug
@@ -26,14 +29,10 @@ const (
 )
 
 var (
-	globalLogger            *slog.Logger
-	enableHideSensitiveData bool
-	disableSubLogs          bool
-
 	DefaultConfig = Config{
 		LogToTerminal:     true,
 		LogToFile:         false,
-		Location:          "/log/",
+		Location:          legacyLocation,
 		FileLogName:       "server_log",
 		FileFormat:        ".%Y-%b-%d-%H-%M.log",
 		MaxAge:            30,
@@ -41,30 +40,142 @@ var (
 		Level:             LevelDebug,
 		CustomWriter:      nil,
 		HideSensitiveData: false,
+		Format:            FormatJSON,
 	}
 )
 
 type (
 	Config struct {
-		LogToTerminal     bool       // Set log output to stdout
-		LogToFile         bool       // Set log output to file
-		Location          string     // Location file log will be save. Default "project_directory/log/".
-		FileLogName       string     // File log name. Default "server_log".
-		FileFormat        string     // Default "FileLogName.2021-Oct-22-00-00.log"
-		MaxAge            int        // Days before deleting log file. Default 30 days.
-		RotationFile      int        // Hour before creating new file. Default 24 hour.
-		Level             slog.Level // Log output level. Default level DEBUG
-		CustomWriter      io.Writer  // Specify custom writer for log output
-		HideSensitiveData bool       // Enable hide sensitive data with struct tag `log:"hide"`
-		DisableSubLogs    bool       // Print to global log instead of append to sublogs
+		LogToTerminal     bool                   // Set log output to stdout
+		LogToFile         bool                   // Set log output to file
+		Location          string                 // Location file log will be save, absolute or relative to working directory. Default "project_directory/log/".
+		FileLogName       string                 // File log name. Default "server_log".
+		ServiceName       string                 // Value of {service} in Location and FileLogName. Default executable name.
+		DirMode           os.FileMode            // Permission of created log directory. Default 0755.
+		FileMode          os.FileMode            // Permission of created log file. Default 0644.
+		FileFormat        string                 // Default "FileLogName.2021-Oct-22-00-00.log"
+		MaxAge            int                    // Days before deleting log file. Default 30 days.
+		RotationFile      int                    // Hour before creating new file. Default 24 hour.
+		MaxSize           int                    // Megabytes before rotating the file log. Default 0, rotate by time only.
+		MaxBackups        int                    // Maximum rotated file log kept. Default 0, keep all until MaxAge.
+		MaxTotalSize      int                    // Megabytes of all file log before deleting the oldest. Default 0, unlimited.
+		Compress          Compression            // Compress rotated file log with CompressGzip or CompressZstd. Default no compression.
+		RotationMode      RotationMode           // RotationExternal write to "Location/FileLogName.log" and reopen by Reopen. Default RotationBuiltin.
+		Level             slog.Level             // Log output level. Default level DEBUG, LevelInfo is the zero value so set LevelSet for INFO
+		LevelSet          bool                   // Use Level as written, so LevelInfo is not replaced by the default DEBUG
+		CustomWriter      io.Writer              // Specify custom writer for log output
+		HideSensitiveData bool                   // Enable hide sensitive data with struct tag `log:"hide"`
+		DisableSubLogs    bool                   // Print to global log instead of append to sublogs
+		LevelRules        map[string]slog.Level  // Level override per package path, example {"repository/*": LevelWarning}
+		Format            Format                 // Log output format. Default FormatJSON
+		Sampling          *SamplingConfig        // Sample global and request log. Default nil, keep everything
+		Async             *AsyncConfig           // Write output from a background goroutine. Default nil, write synchronously
+		ExitFunc          func(code int)         // Called by Fatal after the exit hooks. Default os.Exit
+		MirrorSubLogs     bool                   // Also append InfoCtx, ErrorCtx, ... entries into the request sub-logs
+		Hooks             []Hook                 // Inspect, change or drop every entry before it is written
+		Sinks             []Sink                 // Additional outputs with their own level filter and format
+		DisableTraceLog   bool                   // Skip TRACE entries regardless of Level
+		DisableRequestLog bool                   // Skip REQUEST entries regardless of Level
+		RingBuffer        *RingBuffer            // Also keep written entries in memory, see NewRingBuffer
+		SubLogLimit       *SubLogLimitConfig     // Bound sub-logs kept per request. Default nil, unlimited
+		SubLogRetention   *SubLogRetentionConfig // Write DEBUG and INFO sub-logs only for failed or slow request. Default nil, always write
+		SubLogLevel       *slog.Level            // Minimum level of request sub-logs. Default nil, follow Level
+
+		outputFiles []*reopenFile // File outputs and sinks opened by LoadConfig or ConfigFromEnv, owned by the built logger
+	}
+
+	// Logger is a configured log instance. Multiple loggers with different
+	// configuration can live in the same process.
+	Logger struct {
+		slog              *slog.Logger
+		level             *slog.LevelVar // Current log level, can be changed at runtime
+		handlerLevel      *slog.LevelVar // Lowest level between level and level rules
+		levelRules        atomic.Pointer[levelRules]
+		levelRevert       *time.Timer // Pending auto revert of a temporary level change
+		levelMu           sync.Mutex
+		sampler           *sampler
+		async             []*asyncWriter // Background writer per output, empty when Config.Async is not set
+		closers           []io.Closer    // File output closed by Close
+		reopeners         []reopener     // File output opened again by Reopen
+		pendingMu         sync.Mutex
+		pending           int           // In-flight request and trace saves
+		pendingIdle       chan struct{} // Closed when pending reach zero
+		exitFunc          func(code int)
+		exitHooks         []func()
+		exitMu            sync.Mutex
+		hideSensitiveData atomic.Bool
+		traceDisabled     atomic.Bool
+		requestDisabled   atomic.Bool
+		disableSubLogs    bool
+		mirrorSubLogs     bool
+		hooks             atomic.Pointer[[]Hook]
+		ring              *RingBuffer
+		subLogLimit       *SubLogLimitConfig
+		subLogRetention   *SubLogRetentionConfig
+		subLogLevel       *slog.Level // Nil when sub-logs follow the log level
+		hookMu            sync.Mutex
 	}
 )
 
+// defaultLogger is used by the package level functions. It is stored atomically,
+// so it can be replaced while other goroutines are logging.
+var defaultLogger atomic.Pointer[Logger]
+
+func init() {
+	defaultLogger.Store(New(DefaultConfig))
+}
+
+// Default return the logger used by the package level functions.
+func Default() *Logger {
+	return defaultLogger.Load()
+}
+
+// SetDefault replace the logger used by the package level functions.
+func SetDefault(l *Logger) {
+	if l != nil {
+		defaultLogger.Store(l)
+	}
+}
+
 func Init() {
 	InitWithConfig(DefaultConfig)
 }
 
 func InitWithConfig(cfg Config) {
+	SetDefault(New(cfg))
+}
+
+// TryInitWithConfig is like InitWithConfig but return an error instead of exiting the process.
+func TryInitWithConfig(cfg Config) error {
+	logger, err := cfg.Build()
+	if err != nil {
+		return err
+	}
+	defaultLogger.Store(logger)
+	return nil
+}
+
+// New create a new logger instance from config. When the output can not be created the error is written as FATAL
+// by the default logger, and the process exit through its exit hooks and Config.ExitFunc. New return nil when
+// ExitFunc does not stop the process.
+func New(cfg Config) *Logger {
+	logger, err := cfg.Build()
+	if err != nil {
+		fatalBuildError(cfg, err)
+		return nil
+	}
+	return logger
+}
+
+// Build create a new logger instance from config.
+// File outputs opened by LoadConfig or ConfigFromEnv are closed when the logger can not be built.
+func (cfg Config) Build() (_ *Logger, err error) {
+	defer func() {
+		if err != nil {
+			cfg.closeOutputFiles()
+		}
+	}()
+
 	if cfg.Location == "" {
 		cfg.Location = DefaultConfig.Location
 	}
@@ -80,12 +191,40 @@ func InitWithConfig(cfg Config) {
 	if cfg.RotationFile == 0 {
 		cfg.RotationFile = DefaultConfig.RotationFile
 	}
-	if cfg.Level == 0 {
+	if cfg.Level == 0 && !cfg.LevelSet {
 		cfg.Level = DefaultConfig.Level
 	}
+	for i, sink := range cfg.Sinks {
+		if sink.Writer == nil {
+			return nil, fmt.Errorf("sink %d has no writer", i)
+		}
+	}
 
-	enableHideSensitiveData = cfg.HideSensitiveData
-	disableSubLogs = cfg.DisableSubLogs
+	logger := &Logger{
+		level:           new(slog.LevelVar),
+		handlerLevel:    new(slog.LevelVar),
+		sampler:         newSampler(cfg.Sampling),
+		exitFunc:        cfg.ExitFunc,
+		disableSubLogs:  cfg.DisableSubLogs,
+		mirrorSubLogs:   cfg.MirrorSubLogs,
+		ring:            cfg.RingBuffer,
+		subLogLimit:     cfg.SubLogLimit,
+		subLogRetention: cfg.SubLogRetention,
+	}
+	if cfg.SubLogLevel != nil {
+		subLogLevel := *cfg.SubLogLevel
+		logger.subLogLevel = &subLogLevel
+	}
+	logger.hideSensitiveData.Store(cfg.HideSensitiveData)
+	logger.traceDisabled.Store(cfg.DisableTraceLog)
+	logger.requestDisabled.Store(cfg.DisableRequestLog)
+	logger.SetLevelRules(cfg.LevelRules)
+	logger.AddHook(cfg.Hooks...)
+	logger.SetLevel(cfg.Level)
+	for _, file := range cfg.outputFiles {
+		logger.closers = append(logger.closers, file)
+		logger.reopeners = append(logger.reopeners, file)
+	}
 
 	var output []io.Writer
 
@@ -94,110 +233,237 @@ func InitWithConfig(cfg Config) {
 	}
 
 	if cfg.LogToFile {
-		// Find current project directory
-		currentDirectory, err := os.Getwd()
+		// Resolve absolute location, expanding "~", environment variables and {hostname}, {pid}, {service}
+		fileLocation, err := resolveLocation(cfg.Location, cfg.ServiceName)
+		if err != nil {
+			return nil, err
+		}
+		fileLogName, err := expandPath(cfg.FileLogName, cfg.ServiceName)
 		if err != nil {
-			log.Fatalf("failed find current Directory for setup Log file, %s", err.Error())
+			return nil, err
 		}
+		fileFormat := fmt.Sprintf("%s%s", fileLogName, cfg.FileFormat)
 
-		fileLocation := currentDirectory + cfg.Location
-		fileFormat := fmt.Sprintf("%s%s", cfg.FileLogName, cfg.FileFormat)
+		var fileWriter interface {
+			io.WriteCloser
+			reopener
+		}
 
-		// Initiate file rotate log
-		fileWriter, err := rotatelogs.New(
-			fileLocation+fileFormat,
-			rotatelogs.WithMaxAge(time.Duration(cfg.MaxAge)*24*time.Hour),                // Maximum time before deleting file log
-			rotatelogs.WithRotationTime(time.Duration(cfg.RotationFile)*time.Hour),       // Time before creating new file
-			rotatelogs.WithClock(rotatelogs.Local),                                       // Use local time for file rotation
-			rotatelogs.WithLinkName(fmt.Sprintf("%s.log", fileLocation+cfg.FileLogName))) // Use file shortcut for accessing log file
-		if err != nil {
-			log.Fatalf("failed initiate file log rotation, %s", err.Error())
+		switch cfg.RotationMode {
+		case RotationExternal:
+			fileWriter, err = newReopenFile(fmt.Sprintf("%s.log", fileLocation+fileLogName), cfg.DirMode, cfg.FileMode)
+			if err != nil {
+				return nil, fmt.Errorf("failed initiate file log, %w", err)
+			}
+		case RotationBuiltin:
+			// Initiate file rotate log
+			fileWriter, err = newRotator(rotatorConfig{
+				pattern:      fileLocation + fileFormat,
+				linkName:     fmt.Sprintf("%s.log", fileLocation+fileLogName), // Use file shortcut for accessing log file
+				rotationTime: time.Duration(cfg.RotationFile) * time.Hour,     // Time before creating new file
+				maxAge:       time.Duration(cfg.MaxAge) * 24 * time.Hour,      // Maximum time before deleting file log
+				maxSize:      int64(cfg.MaxSize) * megabyte,
+				maxBackups:   cfg.MaxBackups,
+				maxTotalSize: int64(cfg.MaxTotalSize) * megabyte,
+				compress:     cfg.Compress,
+				dirMode:      cfg.DirMode,
+				fileMode:     cfg.FileMode,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed initiate file log rotation, %w", err)
+			}
+		default:
+			return nil, fmt.Errorf("unknown rotation mode %q, use external or leave it empty", cfg.RotationMode)
 		}
 
 		output = append(output, fileWriter)
+		logger.closers = append(logger.closers, fileWriter)
+		logger.reopeners = append(logger.reopeners, fileWriter)
 	}
 
 	if cfg.CustomWriter != nil {
 		output = append(output, cfg.CustomWriter)
 	}
 
-	globalLogger = slog.New(slog.NewJSONHandler(io.MultiWriter(output...), &slog.HandlerOptions{
-		Level: cfg.Level,
-		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
-			// Remove field msg if the value is empty
-			if a.Key == slog.MessageKey && a.Value.String() == "" {
-				return slog.Attr{}
-			}
+	opts := &slog.HandlerOptions{
+		Level:       logger.handlerLevel,
+		ReplaceAttr: replaceAttr,
+	}
 
-			// Customize the name of the level key and the output string, including custom level values.
-			if a.Key == slog.LevelKey {
-				// Handle custom level values.
-				level := a.Value.Any().(slog.Level)
-				switch {
-				case level < LevelInfo:
-					a.Value = slog.StringValue("DEBUG")
-				case level < LevelWarning:
-					a.Value = slog.StringValue("INFO")
-				case level < LevelError:
-					a.Value = slog.StringValue("WARN")
-				case level < LevelFatal:
-					a.Value = slog.StringValue("ERROR")
-				case level < LevelTrace:
-					a.Value = slog.StringValue("FATAL")
-				case level < LevelRequest:
-					a.Value = slog.StringValue("TRACE")
-				default:
-					a.Value = slog.StringValue("REQUEST")
-				}
-			}
+	// Wrap each output with its own queue, so a slow sink does not block the others
+	withAsync := func(w io.Writer) io.Writer {
+		if cfg.Async == nil {
+			return w
+		}
+		async := newAsyncWriter(w, *cfg.Async)
+		logger.async = append(logger.async, async)
+		return async
+	}
+
+	var sinks []routedHandler
+	if len(output) > 0 || len(cfg.Sinks) == 0 {
+		var writer io.Writer = io.MultiWriter(output...)
+		if len(output) == 1 {
+			writer = output[0] // Keep the original writer so the console format can detect a terminal
+		}
+		sinks = append(sinks, routedHandler{handler: newHandler(cfg.Format, withAsync(writer), opts)})
+	}
 
-			return a
-		},
-	}))
+	for _, sink := range cfg.Sinks {
+		format := sink.Format
+		if format == "" {
+			format = cfg.Format
+		}
+		sinks = append(sinks, routedHandler{levels: sink.Levels, handler: newHandler(format, withAsync(sink.Writer), opts)})
+	}
+
+	var handler slog.Handler = &sinkHandler{sinks: sinks}
+	if len(sinks) == 1 && sinks[0].levels == nil {
+		handler = sinks[0].handler
+	}
+
+	logger.slog = slog.New(handler)
+	return logger, nil
+}
+
+func (l *Logger) Debug(i ...any) {
+	l.logWithCaller(LevelDebug, formatMultipleArguments(i), globalSkipLevel)
+}
+
+func (l *Logger) Debugf(format string, i ...any) {
+	l.logWithCaller(LevelDebug, fmt.Sprintf(format, i...), globalSkipLevel)
+}
+
+func (l *Logger) Info(i ...any) {
+	l.logWithCaller(LevelInfo, formatMultipleArguments(i), globalSkipLevel)
+}
+
+func (l *Logger) Infof(format string, i ...any) {
+	l.logWithCaller(LevelInfo, fmt.Sprintf(format, i...), globalSkipLevel)
+}
+
+func (l *Logger) Warn(i ...any) {
+	l.logWithCaller(LevelWarning, formatMultipleArguments(i), globalSkipLevel)
+}
+
+func (l *Logger) Warnf(format string, i ...any) {
+	l.logWithCaller(LevelWarning, fmt.Sprintf(format, i...), globalSkipLevel)
+}
+
+func (l *Logger) Error(i ...any) {
+	l.logWithCaller(LevelError, formatMultipleArguments(i), globalSkipLevel)
+}
+
+func (l *Logger) Errorf(format string, i ...any) {
+	l.logWithCaller(LevelError, fmt.Sprintf(format, i...), globalSkipLevel)
 }
 
+func (l *Logger) Fatal(i ...any) {
+	l.logWithCaller(LevelFatal, formatMultipleArguments(i), globalSkipLevel)
+	l.exit(1)
+}
+
+func (l *Logger) Fatalf(msg string, i ...any) {
+	l.logWithCaller(LevelFatal, fmt.Sprintf(msg, i...), globalSkipLevel)
+	l.exit(1)
+}
+
+func (l *Logger) logWithCaller(level slog.Level, msg string, skip int, fields ...slog.Attr) {
+	if level < l.handlerLevel.Level() {
+		return // Skip finding the caller, no rule could enable this level
+	}
+
+	caller := zapcore.NewEntryCaller(runtime.Caller(skip))
+	if !l.enabled(level, caller.File) || !l.sampler.allowGlobal(level, msg) {
+		return
+	}
+
+	l.write(context.Background(), &Entry{Level: level, Caller: caller.TrimmedPath(), Message: msg, Fields: fields})
+}
+
+// Package level functions below are thin wrappers around the default logger.
+
 func Debug(i ...any) {
-	logWithCaller(LevelDebug, formatMultipleArguments(i))
+	Default().logWithCaller(LevelDebug, formatMultipleArguments(i), globalSkipLevel)
 }
 
 func Debugf(format string, i ...any) {
-	logWithCaller(LevelDebug, fmt.Sprintf(format, i...))
+	Default().logWithCaller(LevelDebug, fmt.Sprintf(format, i...), globalSkipLevel)
 }
 
 func Info(i ...any) {
-	logWithCaller(LevelInfo, formatMultipleArguments(i))
+	Default().logWithCaller(LevelInfo, formatMultipleArguments(i), globalSkipLevel)
 }
 
 func Infof(format string, i ...any) {
-	logWithCaller(LevelInfo, fmt.Sprintf(format, i...))
+	Default().logWithCaller(LevelInfo, fmt.Sprintf(format, i...), globalSkipLevel)
 }
 
 func Warn(i ...any) {
-	logWithCaller(LevelWarning, formatMultipleArguments(i))
+	Default().logWithCaller(LevelWarning, formatMultipleArguments(i), globalSkipLevel)
 }
 
 func Warnf(format string, i ...any) {
-	logWithCaller(LevelWarning, fmt.Sprintf(format, i...))
+	Default().logWithCaller(LevelWarning, fmt.Sprintf(format, i...), globalSkipLevel)
 }
 
 func Error(i ...any) {
-	logWithCaller(LevelError, formatMultipleArguments(i))
+	Default().logWithCaller(LevelError, formatMultipleArguments(i), globalSkipLevel)
 }
 
 func Errorf(format string, i ...any) {
-	logWithCaller(LevelError, fmt.Sprintf(format, i...))
+	Default().logWithCaller(LevelError, fmt.Sprintf(format, i...), globalSkipLevel)
 }
 
 func Fatal(i ...any) {
-	logWithCaller(LevelFatal, formatMultipleArguments(i))
-	os.Exit(1)
+	logger := Default()
+	logger.logWithCaller(LevelFatal, formatMultipleArguments(i), globalSkipLevel)
+	logger.exit(1)
 }
 
 func Fatalf(msg string, i ...any) {
-	logWithCaller(LevelFatal, fmt.Sprintf(msg, i...))
-	os.Exit(1)
+	logger := Default()
+	logger.logWithCaller(LevelFatal, fmt.Sprintf(msg, i...), globalSkipLevel)
+	logger.exit(1)
 }
 
-func logWithCaller(level slog.Level, msg string) {
-	globalLogger.LogAttrs(context.Background(), level, "", slog.String("caller", GetCaller("", 3)), slog.String("msg", msg))
+// replaceAttr remove empty msg field and customize the level output string.
+func replaceAttr(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) > 0 {
+		return a
+	}
+
+	// Remove field msg if the value is empty
+	if a.Key == slog.MessageKey && a.Value.String() == "" {
+		return slog.Attr{}
+	}
+
+	// Customize the name of the level key and the output string, including custom level values.
+	if a.Key == slog.LevelKey {
+		if level, ok := a.Value.Any().(slog.Level); ok {
+			a.Value = slog.StringValue(levelName(level))
+		}
+	}
+
+	return a
+}
+
+// levelName return the output string of a level, including custom level values.
+func levelName(level slog.Level) string {
+	switch {
+	case level < LevelInfo:
+		return "DEBUG"
+	case level < LevelWarning:
+		return "INFO"
+	case level < LevelError:
+		return "WARN"
+	case level < LevelFatal:
+		return "ERROR"
+	case level < LevelTrace:
+		return "FATAL"
+	case level < LevelRequest:
+		return "TRACE"
+	default:
+		return "REQUEST"
+	}
 }
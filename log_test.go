@@ -0,0 +1,121 @@
+package log
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newTestLogger create a JSON logger writing into buf, without terminal or file output
+func newTestLogger(t *testing.T, buf io.Writer, cfg Config) *Logger {
+	t.Helper()
+
+	cfg.Format = FormatJSON
+	cfg.CustomWriter = buf
+	logger, err := cfg.Build()
+	if err != nil {
+		t.Fatalf("failed build logger, %v", err)
+	}
+	return logger
+}
+
+func TestSetDefaultConcurrent(t *testing.T) {
+	previous := Default()
+	t.Cleanup(func() { SetDefault(previous) })
+
+	var first, second bytes.Buffer
+	loggers := []*Logger{
+		newTestLogger(t, &syncWriter{w: &first}, Config{}),
+		newTestLogger(t, &syncWriter{w: &second}, Config{}),
+	}
+	SetDefault(loggers[0])
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				Debug("swap")
+				Infow("swap", "index", j)
+			}
+		}()
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				SetDefault(loggers[(i+j)%len(loggers)])
+				_ = Default().Level()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	lines := strings.Count(first.String(), "\n") + strings.Count(second.String(), "\n")
+	if lines != 8*100*2 {
+		t.Fatalf("expected %d lines written to the default loggers, got %d", 8*100*2, lines)
+	}
+}
+
+func TestSetDefaultIgnoreNil(t *testing.T) {
+	previous := Default()
+	SetDefault(nil)
+	if Default() != previous {
+		t.Fatal("SetDefault(nil) must keep the current default logger")
+	}
+}
+
+// syncWriter serialize writes of a buffer shared by goroutines
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *syncWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(p)
+}
+
+func TestBuildLevel(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		expect slog.Level
+	}{
+		{"unset level default to debug", Config{}, LevelDebug},
+		{"info with LevelSet", Config{Level: LevelInfo, LevelSet: true}, LevelInfo},
+		{"warning", Config{Level: LevelWarning}, LevelWarning},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := newTestLogger(t, &buf, test.config)
+			if logger.Level() != test.expect {
+				t.Fatalf("expected level %v, got %v", test.expect, logger.Level())
+			}
+
+			logger.Debug("debug")
+			if logged := strings.Contains(buf.String(), `"msg":"debug"`); logged != (test.expect == LevelDebug) {
+				t.Fatalf("expected debug logged %v, got %q", test.expect == LevelDebug, buf.String())
+			}
+		})
+	}
+}
+
+func TestConfigFileLevelInfo(t *testing.T) {
+	cfg, err := LoadConfig(writeConfigFile(t, "level: info\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.LevelSet {
+		t.Fatal("expected level from config file to be marked as set")
+	}
+
+	var buf bytes.Buffer
+	if logger := newTestLogger(t, &buf, cfg); logger.Level() != LevelInfo {
+		t.Fatalf("expected level INFO, got %v", logger.Level())
+	}
+}
@@ -0,0 +1,282 @@
+// Package logtest capture log entries in unit tests.
+//
+//	func TestCreateUser(t *testing.T) {
+//		logtest.New(t)
+//		handler.CreateUser(ctx, input)
+//		logtest.AssertLogged(t, log.LevelError, "duplicate email")
+//	}
+package logtest
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gerins/log"
+)
+
+// WaitTimeout is the maximum time assertions wait for in-flight request.Save and trace.Save
+var WaitTimeout = 5 * time.Second
+
+// recorders find the recorder of a test for the package level assertions
+var recorders sync.Map // map[testing.TB]*Recorder
+
+type (
+	// Recorder is a logger which keep every entry in memory instead of writing it
+	Recorder struct {
+		t       testing.TB
+		logger  *log.Logger
+		mu      sync.Mutex
+		entries []log.Entry
+	}
+
+	// Option change the config of the recording logger, example to enable DisableSubLogs
+	Option func(cfg *log.Config)
+)
+
+// New create a recording logger and install it as the default logger until the test end.
+// The previous default logger is restored by t.Cleanup. Tests using New must not run in parallel
+// because the default logger is shared, use Recorder.Logger for parallel tests.
+// Package level assertions inside t.Run subtests use the recorder installed by the parent test.
+func New(t testing.TB, options ...Option) *Recorder {
+	t.Helper()
+
+	r := &Recorder{t: t}
+	cfg := log.Config{
+		Level:  log.LevelDebug,
+		Format: log.FormatJSON,
+		Hooks:  []log.Hook{log.HookFunc(r.record)},
+
+		// Fatal is recorded instead of exiting the test binary
+		ExitFunc: func(code int) {},
+	}
+	for _, option := range options {
+		option(&cfg)
+	}
+
+	logger, err := cfg.Build()
+	if err != nil {
+		t.Fatalf("logtest: failed create logger, %v", err)
+	}
+	r.logger = logger
+
+	previous := log.Default()
+	log.SetDefault(logger)
+	recorders.Store(t, r)
+
+	t.Cleanup(func() {
+		r.Wait()
+		recorders.Delete(t)
+		log.SetDefault(previous)
+	})
+	return r
+}
+
+// record keep the entry and drop it from the output
+func (r *Recorder) record(entry *log.Entry) bool {
+	r.mu.Lock()
+	r.entries = append(r.entries, *entry)
+	r.mu.Unlock()
+	return false
+}
+
+// Logger return the recording logger
+func (r *Recorder) Logger() *log.Logger {
+	return r.logger
+}
+
+// Wait wait until every in-flight request.Save and trace.Save is recorded
+func (r *Recorder) Wait() {
+	r.t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), WaitTimeout)
+	defer cancel()
+	if err := r.logger.Wait(ctx); err != nil {
+		r.t.Errorf("logtest: %d request log not saved after %s", r.logger.Pending(), WaitTimeout)
+	}
+}
+
+// Entries wait for in-flight saves and return every recorded entry in write order
+func (r *Recorder) Entries() []log.Entry {
+	r.t.Helper()
+	r.Wait()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]log.Entry(nil), r.entries...)
+}
+
+// Level return the recorded entries with the given level
+func (r *Recorder) Level(level slog.Level) []log.Entry {
+	r.t.Helper()
+
+	var result []log.Entry
+	for _, entry := range r.Entries() {
+		if entry.Level == level {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
+// Requests return the request data of every REQUEST entry
+func (r *Recorder) Requests() []*log.RequestData {
+	r.t.Helper()
+
+	var result []*log.RequestData
+	for _, entry := range r.Entries() {
+		if entry.Request != nil {
+			result = append(result, entry.Request)
+		}
+	}
+	return result
+}
+
+// Reset remove the recorded entries
+func (r *Recorder) Reset() {
+	r.t.Helper()
+	r.Wait()
+
+	r.mu.Lock()
+	r.entries = nil
+	r.mu.Unlock()
+}
+
+// Find return the first entry or sub-log with the level and a message containing text.
+// The second result is the REQUEST entry holding the sub-log, nil for global entry.
+func (r *Recorder) Find(level slog.Level, text string) (message string, request *log.Entry, found bool) {
+	r.t.Helper()
+
+	entries := r.Entries()
+	for i := range entries {
+		entry := &entries[i]
+		if entry.Level == level && strings.Contains(entry.Message, text) {
+			return entry.Message, nil, true
+		}
+		if entry.Request == nil {
+			continue
+		}
+		for _, subLog := range entry.Request.SubLogs {
+			if subLogLevel(subLog) == level && strings.Contains(subLog.Message, text) {
+				return subLog.Message, entry, true
+			}
+		}
+	}
+	return "", nil, false
+}
+
+// AssertLogged fail the test when no entry or sub-log has the level and a message containing text
+func (r *Recorder) AssertLogged(level slog.Level, text string) {
+	r.t.Helper()
+	r.assertLogged(r.t, level, text)
+}
+
+// AssertNotLogged fail the test when an entry or sub-log has the level and a message containing text
+func (r *Recorder) AssertNotLogged(level slog.Level, text string) {
+	r.t.Helper()
+	r.assertNotLogged(r.t, level, text)
+}
+
+// assertLogged report the failure to t, which is a subtest when called by the package level AssertLogged
+func (r *Recorder) assertLogged(t testing.TB, level slog.Level, text string) {
+	t.Helper()
+
+	if _, _, found := r.Find(level, text); !found {
+		t.Errorf("logtest: expected %s log containing %q, got:\n%s", levelString(level), text, r.dump())
+	}
+}
+
+func (r *Recorder) assertNotLogged(t testing.TB, level slog.Level, text string) {
+	t.Helper()
+
+	if message, _, found := r.Find(level, text); found {
+		t.Errorf("logtest: unexpected %s log %q", levelString(level), message)
+	}
+}
+
+// dump format the recorded entries for failure message
+func (r *Recorder) dump() string {
+	var sb strings.Builder
+	for _, entry := range r.Entries() {
+		fmt.Fprintf(&sb, "\t%s %s %s\n", levelString(entry.Level), entry.Caller, entry.Message)
+		if entry.Request == nil {
+			continue
+		}
+		for _, subLog := range entry.Request.SubLogs {
+			fmt.Fprintf(&sb, "\t\t+%s %s %s %s\n", subLog.Offset, subLog.Level, subLog.Caller, subLog.Message)
+		}
+	}
+	if sb.Len() == 0 {
+		return "\t(no log entries)\n"
+	}
+	return sb.String()
+}
+
+// AssertLogged fail the test when the recorder installed by New has no matching entry or sub-log
+func AssertLogged(t testing.TB, level slog.Level, text string) {
+	t.Helper()
+	recorderOf(t).assertLogged(t, level, text)
+}
+
+// AssertNotLogged fail the test when the recorder installed by New has a matching entry or sub-log
+func AssertNotLogged(t testing.TB, level slog.Level, text string) {
+	t.Helper()
+	recorderOf(t).assertNotLogged(t, level, text)
+}
+
+// Entries return the entries recorded for the test
+func Entries(t testing.TB) []log.Entry {
+	t.Helper()
+	return recorderOf(t).Entries()
+}
+
+// recorderOf return the recorder installed by New for t. Subtest of t.Run use the recorder of the parent test,
+// found as the recorder which is the current default logger.
+func recorderOf(t testing.TB) *Recorder {
+	t.Helper()
+
+	if r, ok := recorders.Load(t); ok {
+		return r.(*Recorder)
+	}
+
+	var found *Recorder
+	current := log.Default()
+	recorders.Range(func(_, value any) bool {
+		if r := value.(*Recorder); r.logger == current {
+			found = r
+			return false
+		}
+		return true
+	})
+	if found == nil {
+		t.Fatal("logtest: call logtest.New(t) before the assertion")
+	}
+	return found
+}
+
+// subLogLevel parse the level of a sub-log, custom label like GORM never match
+func subLogLevel(subLog log.SubLog) slog.Level {
+	level, err := log.ParseLevel(subLog.Level)
+	if err != nil {
+		return slog.Level(-100) // Unknown level never match
+	}
+	return level
+}
+
+// levelString return the output name of the level, example ERROR or REQUEST
+func levelString(level slog.Level) string {
+	switch level {
+	case log.LevelFatal:
+		return "FATAL"
+	case log.LevelTrace:
+		return "TRACE"
+	case log.LevelRequest:
+		return "REQUEST"
+	default:
+		return level.String()
+	}
+}
@@ -0,0 +1,141 @@
+package logtest_test
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/gerins/log"
+	"github.com/gerins/log/logtest"
+)
+
+// failureRecorder catch the failures reported by the assertions instead of failing the test
+type failureRecorder struct {
+	testing.TB
+	failures []string
+}
+
+func (f *failureRecorder) Errorf(format string, args ...any) {
+	f.failures = append(f.failures, fmt.Sprintf(format, args...))
+}
+
+// Fatal stop the calling goroutine like testing.T, call the assertion from a separate goroutine
+func (f *failureRecorder) Fatal(args ...any) {
+	f.failures = append(f.failures, fmt.Sprint(args...))
+	runtime.Goexit()
+}
+
+func TestAssertLogged(t *testing.T) {
+	logtest.New(t)
+
+	log.Error("duplicate email")
+	log.Infow("user created", "id", 10)
+
+	logtest.AssertLogged(t, log.LevelError, "duplicate email")
+	logtest.AssertLogged(t, log.LevelInfo, "user created")
+	logtest.AssertNotLogged(t, log.LevelWarning, "duplicate email")
+
+	if entries := logtest.Entries(t); len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+}
+
+func TestAssertLoggedReportFailure(t *testing.T) {
+	failures := &failureRecorder{TB: t}
+	rec := logtest.New(failures)
+
+	log.Info("request done")
+
+	logtest.AssertLogged(failures, log.LevelError, "request done")
+	logtest.AssertNotLogged(failures, log.LevelInfo, "request done")
+	if len(failures.failures) != 2 {
+		t.Fatalf("expected 2 failures, got %v", failures.failures)
+	}
+	if !strings.Contains(failures.failures[0], "INFO") || !strings.Contains(failures.failures[0], "request done") {
+		t.Errorf("expected failure message listing the recorded entries, got %q", failures.failures[0])
+	}
+
+	rec.Reset()
+	logtest.AssertNotLogged(failures, log.LevelInfo, "request done")
+	if len(failures.failures) != 2 {
+		t.Fatalf("expected no failure after Reset, got %v", failures.failures[2:])
+	}
+}
+
+func TestAssertSubLogWaitForSave(t *testing.T) {
+	rec := logtest.New(t)
+
+	req := log.NewRequest()
+	req.StatusCode = 409
+	ctx := req.SaveToContext(context.Background())
+	log.Context(ctx).Warn("slow query")
+	log.Context(ctx).SetExtra("userID", 10)
+	req.Save()
+
+	// Save write from a goroutine, the assertion wait for it
+	logtest.AssertLogged(t, log.LevelWarning, "slow query")
+
+	message, entry, found := rec.Find(log.LevelWarning, "slow")
+	if !found || message != "slow query" || entry == nil || entry.Request.StatusCode != 409 {
+		t.Fatalf("expected sub-log inside the REQUEST entry, got %q %v %v", message, entry, found)
+	}
+	requests := rec.Requests()
+	if len(requests) != 1 || requests[0].ExtraData["userID"] != 10 {
+		t.Fatalf("expected 1 request with extra data, got %v", requests)
+	}
+	if len(rec.Level(log.LevelRequest)) != 1 {
+		t.Fatal("expected 1 REQUEST entry")
+	}
+}
+
+func TestFatalIsRecorded(t *testing.T) {
+	logtest.New(t)
+
+	log.Fatal("cannot start")
+	logtest.AssertLogged(t, log.LevelFatal, "cannot start")
+}
+
+func TestRestorePreviousLogger(t *testing.T) {
+	previous := log.Default()
+
+	var rec *logtest.Recorder
+	t.Run("recording", func(t *testing.T) {
+		rec = logtest.New(t)
+		if log.Default() != rec.Logger() {
+			t.Fatal("expected recorder installed as the default logger")
+		}
+	})
+
+	if log.Default() != previous {
+		t.Fatal("expected previous default logger restored after the test")
+	}
+}
+
+func TestSubtestUseParentRecorder(t *testing.T) {
+	logtest.New(t)
+
+	t.Run("child", func(t *testing.T) {
+		log.Warn("from subtest")
+		logtest.AssertLogged(t, log.LevelWarning, "from subtest")
+		if entries := logtest.Entries(t); len(entries) != 1 {
+			t.Fatalf("expected 1 entry, got %d", len(entries))
+		}
+	})
+
+	logtest.AssertLogged(t, log.LevelWarning, "from subtest")
+}
+
+func TestAssertWithoutNew(t *testing.T) {
+	failures := &failureRecorder{TB: t}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		logtest.AssertLogged(failures, log.LevelInfo, "anything")
+	}()
+	<-done
+	if len(failures.failures) != 1 || !strings.Contains(failures.failures[0], "logtest.New") {
+		t.Fatalf("expected failure asking to call New, got %v", failures.failures)
+	}
+}
@@ -19,7 +19,9 @@ func SetLogRequest() echo.MiddlewareFunc {
 			if !ok {
 				ctx = context.Background()
 			}
-			ctx = log.NewRequest().SaveToContext(ctx)
+			requestLog := log.NewRequest()
+			requestLog.Route = c.Path() // Available early so context aware log can stamp the route
+			ctx = requestLog.SaveToContext(ctx)
 			c.Set("ctx", ctx)
 			return next(c)
 		}
@@ -48,6 +50,7 @@ func extractRequestData(ctx context.Context, c echo.Context, req, resp []byte) {
 	requestLog.IP = c.RealIP()
 	requestLog.Method = c.Request().Method
 	requestLog.URL = c.Request().Host + c.Request().URL.String()
+	requestLog.Route = c.Path()
 	requestLog.ReqHeader = getHeader(c, "REQ")
 	requestLog.RespHeader = getHeader(c, "RESP")
 	requestLog.StatusCode = c.Response().Status
@@ -22,6 +22,7 @@ func SaveLogRequest() fiber.Handler {
 		requestLog.IP = getClientIPAdress(c)
 		requestLog.Method = string(c.Request().Header.Method())
 		requestLog.URL = c.Hostname() + string(c.OriginalURL())
+		requestLog.Route = c.Route().Path
 		requestLog.ReqHeader = getHeader(c, "REQ")
 		requestLog.RespHeader = getHeader(c, "RESP")
 		requestLog.StatusCode = c.Response().StatusCode()
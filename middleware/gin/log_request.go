@@ -32,7 +32,9 @@ func SetLogRequest() gin.HandlerFunc {
 		ctx := c.Request.Context()
 
 		// Add the log request to the context
-		newCtxWithLog := log.NewRequest().SaveToContext(ctx)
+		requestLog := log.NewRequest()
+		requestLog.Route = c.FullPath() // Available early so context aware log can stamp the route
+		newCtxWithLog := requestLog.SaveToContext(ctx)
 
 		// Replace the request with the new context
 		c.Request = c.Request.WithContext(newCtxWithLog)
@@ -77,6 +79,7 @@ func extractRequestData(ctx context.Context, c *gin.Context, req, resp []byte) {
 	requestLog.IP = c.ClientIP()
 	requestLog.Method = c.Request.Method
 	requestLog.URL = c.Request.Host + c.Request.URL.String()
+	requestLog.Route = c.FullPath()
 	requestLog.ReqHeader, requestLog.RespHeader = getHeader(c)
 	requestLog.StatusCode = c.Writer.Status()
 
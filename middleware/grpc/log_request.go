@@ -21,6 +21,7 @@ var (
 func SaveLogRequest() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
 		requestLog := log.NewRequest()
+		requestLog.Route = info.FullMethod
 		ctx = requestLog.SaveToContext(ctx)
 
 		// Get request metadata from context
@@ -0,0 +1,138 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// RotationMode choose who rotate the file log
+type RotationMode string
+
+const (
+	RotationBuiltin  RotationMode = ""         // Rotate by the built-in rotator using RotationFile, MaxSize, ...
+	RotationExternal RotationMode = "external" // Write to a fixed path "Location/FileLogName.log" rotated by an external tool like logrotate
+)
+
+type (
+	// reopener is a file output which can be closed and opened again at the same path
+	reopener interface {
+		Reopen() error
+	}
+
+	// reopenFile write into a fixed path, the file is opened again by Reopen after it was moved by logrotate
+	reopenFile struct {
+		path     string
+		dirMode  os.FileMode
+		fileMode os.FileMode
+		mu       sync.Mutex // Serialize Write and Reopen, so no line is split between the old and new file
+		file     *os.File
+	}
+)
+
+func newReopenFile(path string, dirMode, fileMode os.FileMode) (*reopenFile, error) {
+	if dirMode == 0 {
+		dirMode = defaultDirMode
+	}
+	if fileMode == 0 {
+		fileMode = defaultFileMode
+	}
+
+	f := &reopenFile{path: path, dirMode: dirMode, fileMode: fileMode}
+	file, err := f.open()
+	if err != nil {
+		return nil, err
+	}
+	f.file = file
+	return f, nil
+}
+
+// open create the file in append mode. Every write go to the end of the file,
+// so after logrotate copytruncate the next line start at offset 0 instead of leaving a hole.
+func (f *reopenFile) open() (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(f.path), f.dirMode); err != nil {
+		return nil, fmt.Errorf("failed create log directory, %w", err)
+	}
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, f.fileMode)
+	if err != nil {
+		return nil, fmt.Errorf("failed open log file, %w", err)
+	}
+	return file, nil
+}
+
+func (f *reopenFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, ErrClosed
+	}
+	return f.file.Write(p)
+}
+
+// Reopen open the path again and close the previous file. The previous file is kept when opening fail.
+func (f *reopenFile) Reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return ErrClosed
+	}
+	file, err := f.open()
+	if err != nil {
+		return err
+	}
+	f.file.Close()
+	f.file = file
+	return nil
+}
+
+func (f *reopenFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// Reopen close and open the active file of the built-in rotator, example after it was moved
+func (r *rotator) Reopen() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return ErrClosed
+	}
+	if r.file != nil {
+		r.file.Close()
+		r.file = nil
+	}
+	return r.open(time.Now())
+}
+
+// Reopen flush pending output then open the file log again at the same path.
+// Call it after an external tool moved the file, or use HandleReopenSignal.
+func (l *Logger) Reopen() error {
+	errs := []error{l.Flush()}
+	for _, r := range l.reopeners {
+		errs = append(errs, r.Reopen())
+	}
+	return errors.Join(errs...)
+}
+
+// Reopen open the file log of the default logger again
+func Reopen() error {
+	return Default().Reopen()
+}
+
+// HandleReopenSignal reopen the file log of the default logger on SIGHUP
+func HandleReopenSignal() (stop func()) {
+	return Default().HandleReopenSignal()
+}
@@ -0,0 +1,39 @@
+//go:build !windows
+
+package log
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// HandleReopenSignal reopen the file log on SIGHUP, use it with logrotate postrotate script.
+// Call stop to stop listening the signal.
+func (l *Logger) HandleReopenSignal() (stop func()) {
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(signals, syscall.SIGHUP)
+
+	go func() {
+		for {
+			select {
+			case <-signals:
+				if err := l.Reopen(); err != nil {
+					l.logWithCaller(LevelError, "failed reopen log file, "+err.Error(), 1)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(signals)
+			close(done)
+		})
+	}
+}
@@ -0,0 +1,40 @@
+//go:build !windows
+
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestHandleReopenSignal(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	logger := newExternalRotationLogger(t, dir)
+	stop := logger.HandleReopenSignal()
+	defer stop()
+
+	logger.Info("before rotate")
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
+
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(5 * time.Millisecond) {
+		if _, err := os.Stat(path); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the file opened again after SIGHUP")
+		}
+	}
+	logger.Info("after reopen")
+
+	if content := readFile(t, path); !strings.Contains(content, "after reopen") || strings.Contains(content, "before rotate") {
+		t.Fatalf("expected only the line after SIGHUP in the new file, got %q", content)
+	}
+}
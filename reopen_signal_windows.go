@@ -0,0 +1,8 @@
+//go:build windows
+
+package log
+
+// HandleReopenSignal is not supported on windows because SIGHUP does not exist, call Reopen instead.
+func (l *Logger) HandleReopenSignal() (stop func()) {
+	return func() {}
+}
@@ -0,0 +1,114 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newExternalRotationLogger build a logger writing to dir/app.log without terminal output
+func newExternalRotationLogger(t *testing.T, dir string) *Logger {
+	t.Helper()
+
+	logger, err := Config{LogToFile: true, Location: dir, FileLogName: "app", RotationMode: RotationExternal}.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { logger.Close() })
+	return logger
+}
+
+func TestExternalRotationReopen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	logger := newExternalRotationLogger(t, dir)
+
+	logger.Info("before rotate")
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("after move")
+	if err := logger.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("after reopen")
+
+	tests := []struct {
+		file     string
+		contains []string
+		absent   []string
+	}{
+		{path + ".1", []string{"before rotate", "after move"}, []string{"after reopen"}},
+		{path, []string{"after reopen"}, []string{"before rotate", "after move"}},
+	}
+	for _, test := range tests {
+		content := readFile(t, test.file)
+		for _, text := range test.contains {
+			if !strings.Contains(content, text) {
+				t.Errorf("%s: expected %q, got %q", test.file, text, content)
+			}
+		}
+		for _, text := range test.absent {
+			if strings.Contains(content, text) {
+				t.Errorf("%s: expected no %q, got %q", test.file, text, content)
+			}
+		}
+	}
+}
+
+func TestExternalRotationCopyTruncate(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	logger := newExternalRotationLogger(t, dir)
+
+	logger.Info("before truncate")
+	if err := os.Truncate(path, 0); err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("after truncate")
+
+	content := readFile(t, path)
+	if content == "" || content[0] != '{' || strings.Contains(content, "before truncate") {
+		t.Fatalf("expected next line written at offset 0 after truncate, got %q", content)
+	}
+}
+
+func TestReopenFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "log", "app.log")
+	file, err := newReopenFile(path, 0, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected file created with mode 0600, got %v %v", info, err)
+	}
+
+	// Opening fail when the directory is replaced by a file, the previous file is kept
+	if err := os.RemoveAll(filepath.Dir(path)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Dir(path), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Reopen(); err == nil {
+		t.Fatal("expected Reopen error")
+	}
+	if _, err := file.Write([]byte("line\n")); err != nil {
+		t.Fatalf("expected the previous file kept after a failed Reopen, got %v", err)
+	}
+
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("expected second Close to be a no-op, got %v", err)
+	}
+	if _, err := file.Write([]byte("line\n")); err != ErrClosed {
+		t.Fatalf("expected ErrClosed after Close, got %v", err)
+	}
+	if err := file.Reopen(); err != ErrClosed {
+		t.Fatalf("expected ErrClosed from Reopen after Close, got %v", err)
+	}
+}
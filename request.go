@@ -2,10 +2,15 @@ package log
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
+	"runtime"
+	"strings"
 	"sync"
 	"time"
+
+	"go.uber.org/zap/zapcore"
 )
 
 var (
@@ -13,6 +18,7 @@ var (
 	logRequestKey   contextKey
 )
 
+// Sub-log level, the caller is kept separately in SubLog.Caller
 const (
 	subLevelDebug = "DEBUG"
 	subLevelInfo  = "INFO"
@@ -26,69 +32,170 @@ type (
 
 	// Data Model for tracking information of incoming request
 	request struct {
+		logger     *Logger // Logger who created this request
 		traceID    string
 		IP         string
 		Method     string
 		URL        string
+		Route      string // Route pattern, example "/users/:id". Used for sampling, fallback to URL path
 		ReqHeader  any
 		ReqBody    any
 		RespHeader any
 		RespBody   any
 		StatusCode int             // HTTP status code or other code
 		timeStart  time.Time       // Capture when the request start
-		ExtraData  map[string]any  // Additional data
-		subLogs    []subLog        // Sub logging data
+		mu         sync.Mutex      // Guard extraData, subLogs and hasError, they are written from many goroutines
+		extraData  map[string]any  // Additional data, use SetExtra and GetExtra
+		subLogs    subLogBuffer    // Sub logging data, bounded by Config.SubLogLimit
+		hasError   bool            // Any ERROR or FATAL sub-log recorded
 		WaitGroup  *sync.WaitGroup // Wait for all goroutine finish before printing log
 	}
 
-	// Data model for saving all log output in single request flow
-	subLog struct {
-		Level   string `json:"level"`
-		Message string `json:"msg"`
+	// SubLog is data model for saving all log output in single request flow.
+	// Offset is the elapsed time since the request start, written in millisecond like totalDuration.
+	SubLog struct {
+		Time      time.Time      `json:"time"`
+		Offset    time.Duration  `json:"-"`
+		Level     string         `json:"level"`
+		Caller    string         `json:"caller,omitempty"`
+		Goroutine uint64         `json:"goroutine,omitempty"`
+		Message   string         `json:"msg"`
+		Fields    map[string]any `json:"fields,omitempty"`
 	}
 )
 
-// NewRequest will create new log data model for incoming request
+// NewRequest will create new log data model for incoming request using the default logger
 func NewRequest() *request {
+	return Default().NewRequest()
+}
+
+// NewRequest will create new log data model for incoming request bound to this logger
+func (l *Logger) NewRequest() *request {
 	return &request{
+		logger:    l,
 		traceID:   generateRandomString(20),
 		timeStart: time.Now(),
-		ExtraData: make(map[string]any),
+		extraData: make(map[string]any),
+		subLogs:   subLogBuffer{limit: l.subLogLimit},
 		WaitGroup: new(sync.WaitGroup),
 	}
 }
 
 // Save will save current request information to log file
 func (m *request) Save() {
+	if !m.logger.RequestEnabled() {
+		return
+	}
+
+	done := m.logger.trackSave()
 	go func() {
+		defer done()
 		m.WaitGroup.Wait() // Wait for all goroutine finish before logging
 
-		if enableHideSensitiveData {
-			for _, data := range m.ExtraData {
-				maskSensitiveData(data)
+		// Copy under lock, so goroutines still logging after Save do not race with the output
+		data := m.snapshot()
+		duration := time.Since(m.timeStart)
+
+		if !m.logger.sampler.allowRequest(m, data.hasError) {
+			return
+		}
+
+		// Successful and fast request drop DEBUG and INFO sub-logs
+		var subLogDiscarded int
+		if retention := m.logger.subLogRetention; retention != nil && !retention.keepAll(m, data.hasError, duration) {
+			data.subLogs, subLogDiscarded = summarySubLogs(data.subLogs)
+		}
+
+		if m.logger.hideSensitiveData.Load() {
+			for _, value := range data.extraData {
+				maskSensitiveData(value)
 			}
 			maskSensitiveData(m.ReqBody)
 			maskSensitiveData(m.RespBody)
+			for _, sub := range data.subLogs {
+				for _, field := range sub.Fields {
+					maskSensitiveData(field)
+				}
+			}
 		}
 
-		globalLogger.LogAttrs(context.Background(), LevelRequest, "",
-			slog.String("caller", GetCaller("", 1)),
-			slog.String(traceID, m.traceID),
-			slog.String("ip", m.IP),
-			slog.String("method", m.Method),
-			slog.String("url", m.URL),
-			slog.Int("statusCode", m.StatusCode),
-			slog.Int64("totalDuration", time.Since(m.timeStart).Milliseconds()),
-			slog.Any("requestHeader", m.ReqHeader),
-			slog.Any("requestBody", m.ReqBody),
-			slog.Any("responseHeader", m.RespHeader),
-			slog.Any("responseBody", m.RespBody),
-			slog.Any("extraData", m.ExtraData),
-			slog.Any("subLog", m.subLogs),
-		)
+		m.logger.write(context.Background(), &Entry{
+			Level:   LevelRequest,
+			Caller:  GetCaller("", 1),
+			TraceID: m.traceID,
+			Request: &RequestData{
+				IP:              m.IP,
+				Method:          m.Method,
+				URL:             m.URL,
+				Route:           m.Route,
+				StatusCode:      m.StatusCode,
+				Duration:        duration,
+				RequestHeader:   m.ReqHeader,
+				RequestBody:     m.ReqBody,
+				ResponseHeader:  m.RespHeader,
+				ResponseBody:    m.RespBody,
+				ExtraData:       data.extraData,
+				SubLogs:         data.subLogs,
+				SubLogDropped:   data.subLogDropped,
+				Truncated:       data.truncated,
+				SubLogDiscarded: subLogDiscarded,
+			},
+		})
 	}()
 }
 
+// requestSnapshot is a copy of the data written from many goroutines
+type requestSnapshot struct {
+	extraData     map[string]any
+	subLogs       []SubLog
+	subLogDropped int
+	truncated     bool
+	hasError      bool
+}
+
+// snapshot return a copy of extra data and sub-logs
+func (m *request) snapshot() requestSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	extraData := make(map[string]any, len(m.extraData))
+	for key, value := range m.extraData {
+		extraData[key] = value
+	}
+	return requestSnapshot{
+		extraData:     extraData,
+		subLogs:       m.subLogs.list(),
+		subLogDropped: m.subLogs.dropped,
+		truncated:     m.subLogs.truncated,
+		hasError:      m.hasError,
+	}
+}
+
+// SetExtra add additional data printed under extraData, safe to call from many goroutines
+func (m *request) SetExtra(key string, value any) {
+	m.mu.Lock()
+	m.extraData[key] = value
+	m.mu.Unlock()
+}
+
+// GetExtra return additional data added by SetExtra
+func (m *request) GetExtra(key string) (value any, found bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	value, found = m.extraData[key]
+	return value, found
+}
+
+// GetExtraAs return additional data converted to T, found is false when the key is missing or has other type
+func GetExtraAs[T any](m *request, key string) (value T, found bool) {
+	raw, ok := m.GetExtra(key)
+	if !ok {
+		return value, false
+	}
+	value, found = raw.(T)
+	return value, found
+}
+
 // SetTraceID is used for set trace id as your preferences format.
 func (m *request) SetTraceID(traceID string) {
 	m.traceID = traceID
@@ -118,134 +225,179 @@ func (m *request) RecordDuration(processName string) processData {
 }
 
 func (m *request) Debug(i ...any) {
-	msg := formatMultipleArguments(i)
-
-	if disableSubLogs {
-		m.globalLog(LevelDebug, msg, "")
+	if m.skip(LevelDebug) {
 		return
 	}
-
-	m.subLogs = append(m.subLogs, subLog{Level: GetCaller(subLevelDebug, subLogSkipLevel), Message: msg})
+	m.log(LevelDebug, formatMultipleArguments(i))
 }
 
 func (m *request) Debugf(format string, i ...any) {
-	msg := fmt.Sprintf(format, i...)
-
-	if disableSubLogs {
-		m.globalLog(LevelDebug, msg, "")
+	if m.skip(LevelDebug) {
 		return
 	}
-
-	m.subLogs = append(m.subLogs, subLog{Level: GetCaller(subLevelDebug, subLogSkipLevel), Message: msg})
+	m.log(LevelDebug, fmt.Sprintf(format, i...))
 }
 
 func (m *request) Info(i ...any) {
-	msg := formatMultipleArguments(i)
-
-	if disableSubLogs {
-		m.globalLog(LevelInfo, msg, "")
+	if m.skip(LevelInfo) {
 		return
 	}
-
-	m.subLogs = append(m.subLogs, subLog{Level: GetCaller(subLevelInfo, subLogSkipLevel), Message: msg})
+	m.log(LevelInfo, formatMultipleArguments(i))
 }
 
 func (m *request) Infof(format string, i ...any) {
-	msg := fmt.Sprintf(format, i...)
-
-	if disableSubLogs {
-		m.globalLog(LevelInfo, msg, "")
+	if m.skip(LevelInfo) {
 		return
 	}
-
-	m.subLogs = append(m.subLogs, subLog{Level: GetCaller(subLevelInfo, subLogSkipLevel), Message: msg})
+	m.log(LevelInfo, fmt.Sprintf(format, i...))
 }
 
 func (m *request) Warn(i ...any) {
-	msg := formatMultipleArguments(i)
-
-	if disableSubLogs {
-		m.globalLog(LevelWarning, msg, "")
+	if m.skip(LevelWarning) {
 		return
 	}
-
-	m.subLogs = append(m.subLogs, subLog{Level: GetCaller(subLevelWarn, subLogSkipLevel), Message: msg})
+	m.log(LevelWarning, formatMultipleArguments(i))
 }
 
 func (m *request) Warnf(format string, i ...any) {
-	msg := fmt.Sprintf(format, i...)
-
-	if disableSubLogs {
-		m.globalLog(LevelWarning, msg, "")
+	if m.skip(LevelWarning) {
 		return
 	}
-
-	m.subLogs = append(m.subLogs, subLog{Level: GetCaller(subLevelWarn, subLogSkipLevel), Message: msg})
+	m.log(LevelWarning, fmt.Sprintf(format, i...))
 }
 
 func (m *request) Error(i ...any) {
-	msg := formatMultipleArguments(i)
-
-	if disableSubLogs {
-		m.globalLog(LevelError, msg, "")
+	if m.skip(LevelError) {
 		return
 	}
-
-	m.subLogs = append(m.subLogs, subLog{Level: GetCaller(subLevelError, subLogSkipLevel), Message: msg})
+	m.log(LevelError, formatMultipleArguments(i))
 }
 
 func (m *request) Errorf(format string, i ...any) {
-	msg := fmt.Sprintf(format, i...)
-
-	if disableSubLogs {
-		m.globalLog(LevelError, msg, "")
+	if m.skip(LevelError) {
 		return
 	}
-
-	m.subLogs = append(m.subLogs, subLog{Level: GetCaller(subLevelError, subLogSkipLevel), Message: msg})
+	m.log(LevelError, fmt.Sprintf(format, i...))
 }
 
 func (m *request) Fatal(i ...any) {
-	msg := formatMultipleArguments(i)
+	if m.skip(LevelFatal) {
+		return
+	}
+	m.log(LevelFatal, formatMultipleArguments(i))
+}
 
-	if disableSubLogs {
-		m.globalLog(LevelFatal, msg, "")
+func (m *request) Fatalf(format string, i ...any) {
+	if m.skip(LevelFatal) {
 		return
 	}
+	m.log(LevelFatal, fmt.Sprintf(format, i...))
+}
 
-	m.subLogs = append(m.subLogs, subLog{Level: GetCaller(subLevelFatal, subLogSkipLevel), Message: msg})
+// SubLog append a sub-log with custom label and caller, example SubLog("[GORM] repository/user.go:20", "SELECT ...")
+func (m *request) SubLog(levelAndCaller, message string) {
+	m.customSubLog(LevelInfo, levelAndCaller, message)
 }
 
-func (m *request) Fatalf(format string, i ...any) {
-	msg := fmt.Sprintf(format, i...)
+// SubLogError append a sub-log with custom label like SubLog and mark the request as failed,
+// so Config.SubLogRetention and sampling keep every sub-log of the request
+func (m *request) SubLogError(levelAndCaller, message string) {
+	m.customSubLog(LevelError, levelAndCaller, message)
+}
 
-	if disableSubLogs {
-		m.globalLog(LevelFatal, msg, "")
+// customSubLog append a sub-log with custom label, level is used when sub-logs is disabled
+func (m *request) customSubLog(level slog.Level, levelAndCaller, message string) {
+	if m.logger.disableSubLogs {
+		m.globalLog(level, message, levelAndCaller)
 		return
 	}
 
-	m.subLogs = append(m.subLogs, subLog{Level: GetCaller(subLevelFatal, subLogSkipLevel), Message: msg})
+	label, caller := splitLevelAndCaller(levelAndCaller)
+	m.appendSubLog(m.newSubLog(label, caller, message, nil), level >= LevelError)
 }
 
-func (m *request) SubLog(levelAndCaller, message string) {
-	if disableSubLogs {
-		m.globalLog(LevelInfo, message, levelAndCaller)
+// skip report whether the level is disabled for this request, checked before formatting the message
+func (m *request) skip(level slog.Level) bool {
+	return level < m.logger.subLogMinLevel()
+}
+
+// log append message to sub-logs, or print it to global log when sub-logs is disabled
+func (m *request) log(level slog.Level, msg string, fields ...slog.Attr) {
+	m.record(level, zapcore.NewEntryCaller(runtime.Caller(subLogSkipLevel)), msg, fields)
+}
+
+// record append a sub-log from the caller, or print it to global log when sub-logs is disabled
+func (m *request) record(level slog.Level, caller zapcore.EntryCaller, msg string, fields []slog.Attr) {
+	if m.logger.disableSubLogs {
+		if m.logger.enabled(level, caller.File) && m.logger.sampler.allowGlobal(level, msg) {
+			m.globalLog(level, msg, callerPath(caller), fields...)
+		}
 		return
 	}
 
-	m.subLogs = append(m.subLogs, subLog{Level: levelAndCaller, Message: message})
+	if !m.logger.subLogEnabled(level, caller.File) {
+		return
+	}
+
+	m.appendSubLog(m.newSubLog(levelName(level), callerPath(caller), msg, attrsToMap(fields)), level >= LevelError)
 }
 
-func (m *request) globalLog(level slog.Level, msg string, caller string) {
-	if caller == "" {
-		caller = GetCaller("", subLogSkipLevel+1)
+// newSubLog create sub-log stamped with the current time and goroutine
+func (m *request) newSubLog(level, caller, msg string, fields map[string]any) SubLog {
+	now := time.Now()
+	return SubLog{
+		Time:      now,
+		Offset:    now.Sub(m.timeStart),
+		Level:     level,
+		Caller:    caller,
+		Goroutine: goroutineID(),
+		Message:   msg,
+		Fields:    fields,
 	}
+}
 
-	attrs := []slog.Attr{
-		slog.String("caller", caller),
-		slog.String(traceID, m.traceID),
-		slog.String("msg", msg),
+// appendSubLog add sub-log under lock, failed mark the request as having an error
+func (m *request) appendSubLog(subLog SubLog, failed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.subLogs.add(subLog)
+	if failed {
+		m.hasError = true
+	}
+}
+
+// MarshalJSON write Offset as millisecond with microsecond precision
+func (s SubLog) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Time      time.Time      `json:"time"`
+		Offset    float64        `json:"offset"`
+		Level     string         `json:"level"`
+		Caller    string         `json:"caller,omitempty"`
+		Goroutine uint64         `json:"goroutine,omitempty"`
+		Message   string         `json:"msg"`
+		Fields    map[string]any `json:"fields,omitempty"`
+	}{
+		Time:      s.Time,
+		Offset:    float64(s.Offset.Microseconds()) / 1e3,
+		Level:     s.Level,
+		Caller:    s.Caller,
+		Goroutine: s.Goroutine,
+		Message:   s.Message,
+		Fields:    s.Fields,
+	})
+}
+
+// splitLevelAndCaller split "[LEVEL] caller" into level and caller, other format is kept as level
+func splitLevelAndCaller(levelAndCaller string) (level, caller string) {
+	if strings.HasPrefix(levelAndCaller, "[") {
+		if index := strings.Index(levelAndCaller, "] "); index > 0 {
+			return levelAndCaller[1:index], levelAndCaller[index+2:]
+		}
 	}
+	return levelAndCaller, ""
+}
 
-	globalLogger.LogAttrs(context.Background(), level, "", attrs...)
+func (m *request) globalLog(level slog.Level, msg string, caller string, fields ...slog.Attr) {
+	m.logger.write(context.Background(), &Entry{Level: level, Caller: caller, TraceID: m.traceID, Message: msg, Fields: fields})
 }
@@ -0,0 +1,306 @@
+package log
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// entryRecorder keep the entries written by a logger, use its hook in Config.Hooks
+type entryRecorder struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+func (r *entryRecorder) hook(entry *Entry) bool {
+	r.mu.Lock()
+	r.entries = append(r.entries, *entry)
+	r.mu.Unlock()
+	return false
+}
+
+func (r *entryRecorder) requests(t *testing.T, logger *Logger) []*RequestData {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := logger.Wait(ctx); err != nil {
+		t.Fatalf("request log not saved, %v", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var requests []*RequestData
+	for _, entry := range r.entries {
+		if entry.Request != nil {
+			requests = append(requests, entry.Request)
+		}
+	}
+	return requests
+}
+
+func newRecordingLogger(t *testing.T, cfg Config) (*Logger, *entryRecorder) {
+	t.Helper()
+
+	recorder := &entryRecorder{}
+	cfg.Hooks = append(cfg.Hooks, HookFunc(recorder.hook))
+	logger, err := cfg.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return logger, recorder
+}
+
+func TestRequestConcurrentSubLogsAndExtraData(t *testing.T) {
+	logger, recorder := newRecordingLogger(t, Config{})
+
+	const workers = 50
+	req := logger.NewRequest()
+	ctx := req.SaveToContext(context.Background())
+
+	for i := 0; i < workers; i++ {
+		req.WaitGroup.Add(1)
+		go func(i int) {
+			defer req.WaitGroup.Done()
+
+			Context(ctx).Debug("debug", i)
+			Context(ctx).Infof("info %d", i)
+			Context(ctx).Errorw("error", "worker", i)
+			Context(ctx).SubLog("[GORM] repository/user.go:20", fmt.Sprintf("SELECT %d", i))
+
+			Context(ctx).SetExtra(fmt.Sprintf("worker-%d", i), i)
+			if value, found := GetExtraAs[int](Context(ctx), fmt.Sprintf("worker-%d", i)); !found || value != i {
+				t.Errorf("worker %d: expected own extra data, got %v %v", i, value, found)
+			}
+
+			trace := NewTrace(http.MethodGet, fmt.Sprintf("https://api.local/users/%d", i), nil, nil, true)
+			trace.RawRespBody = []byte(fmt.Sprintf(`{"id":%d}`, i))
+			trace.Save(ctx, &http.Response{StatusCode: http.StatusOK, Header: http.Header{}})
+		}(i)
+	}
+	req.Save()
+
+	requests := recorder.requests(t, logger)
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 REQUEST entry, got %d", len(requests))
+	}
+	data := requests[0]
+
+	if len(data.SubLogs) != workers*4 {
+		t.Fatalf("expected %d sub-logs, got %d", workers*4, len(data.SubLogs))
+	}
+	levels := make(map[string]int)
+	for _, subLog := range data.SubLogs {
+		levels[subLog.Level]++
+	}
+	for _, level := range []string{"DEBUG", "INFO", "ERROR", "GORM"} {
+		if levels[level] != workers {
+			t.Errorf("expected %d %s sub-logs, got %d", workers, level, levels[level])
+		}
+	}
+
+	if len(data.ExtraData) != workers*2 {
+		t.Fatalf("expected %d extra data, got %d", workers*2, len(data.ExtraData))
+	}
+	for i := 0; i < workers; i++ {
+		if value := data.ExtraData[fmt.Sprintf("worker-%d", i)]; value != i {
+			t.Errorf("expected worker-%d extra data %d, got %v", i, i, value)
+		}
+		traced, ok := data.ExtraData[fmt.Sprintf("https://api.local/users/%d", i)].(*trace)
+		if !ok || traced.StatusCode != http.StatusOK || fmt.Sprint(traced.RespBody) != fmt.Sprintf("map[id:%d]", i) {
+			t.Errorf("expected trace %d in extra data, got %#v", i, data.ExtraData[fmt.Sprintf("https://api.local/users/%d", i)])
+		}
+	}
+}
+
+func TestRequestSaveWhileLogging(t *testing.T) {
+	logger, recorder := newRecordingLogger(t, Config{})
+
+	const (
+		workers = 20
+		lines   = 50
+	)
+	req := logger.NewRequest()
+
+	// Goroutines not tracked by WaitGroup keep logging while Save is called many times
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < lines; j++ {
+				req.Infof("worker %d line %d", i, j)
+				req.SetExtra(fmt.Sprintf("worker-%d", i), j)
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			req.Save()
+		}()
+	}
+	wg.Wait()
+
+	requests := recorder.requests(t, logger)
+	if len(requests) != workers {
+		t.Fatalf("expected %d REQUEST entries, got %d", workers, len(requests))
+	}
+	for _, data := range requests {
+		if len(data.SubLogs) > workers*lines {
+			t.Fatalf("expected at most %d sub-logs, got %d", workers*lines, len(data.SubLogs))
+		}
+	}
+
+	// Save after every worker finished see every sub-log and the last extra data
+	req.Save()
+	requests = recorder.requests(t, logger)
+	final := requests[len(requests)-1]
+	if len(final.SubLogs) != workers*lines {
+		t.Fatalf("expected %d sub-logs after every worker finished, got %d", workers*lines, len(final.SubLogs))
+	}
+	for i := 0; i < workers; i++ {
+		if value := final.ExtraData[fmt.Sprintf("worker-%d", i)]; value != lines-1 {
+			t.Errorf("expected worker-%d last extra data %d, got %v", i, lines-1, value)
+		}
+	}
+}
+
+func TestRequestErrorSubLogMarkFailed(t *testing.T) {
+	logger, _ := newRecordingLogger(t, Config{})
+
+	req := logger.NewRequest()
+	req.Info("ok")
+	if req.snapshot().hasError {
+		t.Fatal("expected request without error")
+	}
+	req.Error("failed")
+	if !req.snapshot().hasError {
+		t.Fatal("expected ERROR sub-log to mark the request failed")
+	}
+}
+
+// formatCounter count how many times the message is formatted
+type formatCounter struct{ count *int }
+
+func (f formatCounter) String() string {
+	*f.count++
+	return "formatted"
+}
+
+func TestRequestSubLogLevel(t *testing.T) {
+	debug := LevelDebug
+	tests := []struct {
+		name        string
+		config      Config
+		expectDebug bool
+	}{
+		{"info level drop debug", Config{Level: LevelInfo, LevelSet: true}, false},
+		{"unset level keep debug", Config{}, true},
+		{"sub-log level debug", Config{Level: LevelInfo, LevelSet: true, SubLogLevel: &debug}, true},
+		{"level rule debug", Config{Level: LevelInfo, LevelSet: true, LevelRules: map[string]slog.Level{"request_test.go": LevelDebug}}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			logger, recorder := newRecordingLogger(t, test.config)
+
+			var formatted int
+			req := logger.NewRequest()
+			req.Debug("debug ", formatCounter{&formatted})
+			req.Debugf("debugf %v", formatCounter{&formatted})
+			req.Debugw("debugw", "value", formatCounter{&formatted})
+			req.Info("info")
+			req.Save()
+
+			requests := recorder.requests(t, logger)
+			if len(requests) != 1 {
+				t.Fatalf("expected 1 REQUEST entry, got %d", len(requests))
+			}
+			subLogs := requests[0].SubLogs
+
+			if !test.expectDebug {
+				if len(subLogs) != 1 || subLogs[0].Level != "INFO" {
+					t.Fatalf("expected only the INFO sub-log, got %+v", subLogs)
+				}
+				if formatted != 0 {
+					t.Fatalf("expected disabled sub-log not to format the message, formatted %d times", formatted)
+				}
+				return
+			}
+			if len(subLogs) != 4 || subLogs[0].Level != "DEBUG" {
+				t.Fatalf("expected DEBUG sub-logs kept, got %+v", subLogs)
+			}
+		})
+	}
+}
+
+func TestSubLogMetadata(t *testing.T) {
+	logger, recorder := newRecordingLogger(t, Config{})
+
+	req := logger.NewRequest()
+	ctx := req.SaveToContext(context.Background())
+	start := time.Now()
+	Context(ctx).Infow("first", "id", 10)
+	time.Sleep(5 * time.Millisecond)
+
+	req.WaitGroup.Add(1)
+	go func() {
+		defer req.WaitGroup.Done()
+		Context(ctx).Warn("from goroutine")
+	}()
+	req.Save()
+
+	requests := recorder.requests(t, logger)
+	if len(requests) != 1 || len(requests[0].SubLogs) != 2 {
+		t.Fatalf("expected 1 REQUEST entry with 2 sub-logs, got %+v", requests)
+	}
+	first, second := requests[0].SubLogs[0], requests[0].SubLogs[1]
+
+	if first.Level != "INFO" || !strings.Contains(first.Caller, "request_test.go:") || first.Message != "first" || first.Fields["id"] != int64(10) {
+		t.Fatalf("expected separate level, caller, message and fields, got %+v", first)
+	}
+	if first.Time.Before(start.Add(-time.Second)) || first.Offset < 0 || second.Offset-first.Offset < 5*time.Millisecond {
+		t.Fatalf("expected time and increasing offset, got %v %v %v", first.Time, first.Offset, second.Offset)
+	}
+	if first.Goroutine == 0 || second.Goroutine == 0 || first.Goroutine == second.Goroutine {
+		t.Fatalf("expected goroutine id of each caller, got %d and %d", first.Goroutine, second.Goroutine)
+	}
+}
+
+func TestSubLogMarshalJSON(t *testing.T) {
+	subLog := SubLog{
+		Time:    time.Date(2021, 10, 22, 8, 30, 0, 0, time.UTC),
+		Offset:  1500 * time.Microsecond,
+		Level:   "GORM",
+		Caller:  "repository/user.go:20",
+		Message: "SELECT 1",
+	}
+	data, err := json.Marshal(subLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"time":"2021-10-22T08:30:00Z","offset":1.5,"level":"GORM","caller":"repository/user.go:20","msg":"SELECT 1"}`
+	if string(data) != expected {
+		t.Fatalf("expected %s, got %s", expected, data)
+	}
+}
+
+func TestSplitLevelAndCaller(t *testing.T) {
+	tests := []struct {
+		input, level, caller string
+	}{
+		{"[GORM] repository/user.go:20", "GORM", "repository/user.go:20"},
+		{"[DURATION] usecase/user.go:10", "DURATION", "usecase/user.go:10"},
+		{"CUSTOM", "CUSTOM", ""},
+		{"[BROKEN", "[BROKEN", ""},
+	}
+	for _, test := range tests {
+		if level, caller := splitLevelAndCaller(test.input); level != test.level || caller != test.caller {
+			t.Errorf("%q: expected %q %q, got %q %q", test.input, test.level, test.caller, level, caller)
+		}
+	}
+}
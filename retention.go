@@ -0,0 +1,59 @@
+package log
+
+import (
+	"time"
+)
+
+type (
+	// SubLogRetentionConfig keep DEBUG and INFO sub-logs only for failed or slow requests.
+	// Other requests are written as a compact summary with WARN and above and custom label sub-logs.
+	SubLogRetentionConfig struct {
+		MinStatus      int                      // Status code which keep every sub-log. Default 500
+		SlowThreshold  time.Duration            // Duration which keep every sub-log. Default 0, disabled
+		RouteThreshold map[string]time.Duration // Slow threshold per route, example {"GET /report": 10 * time.Second, "/users": time.Second}
+	}
+)
+
+// keepAll report whether the request ended badly and every sub-log should be written
+func (c *SubLogRetentionConfig) keepAll(m *request, hasError bool, duration time.Duration) bool {
+	minStatus := c.MinStatus
+	if minStatus <= 0 {
+		minStatus = 500
+	}
+	if hasError || m.StatusCode >= minStatus {
+		return true
+	}
+
+	threshold := c.SlowThreshold
+	if routeThreshold, found := c.routeThreshold(m); found {
+		threshold = routeThreshold
+	}
+	return threshold > 0 && duration >= threshold
+}
+
+// routeThreshold find the threshold by "METHOD route" first, then by route only
+func (c *SubLogRetentionConfig) routeThreshold(m *request) (time.Duration, bool) {
+	if len(c.RouteThreshold) == 0 {
+		return 0, false
+	}
+
+	route := m.routeKey()
+	if threshold, ok := c.RouteThreshold[m.Method+" "+route]; ok {
+		return threshold, true
+	}
+	threshold, ok := c.RouteThreshold[route]
+	return threshold, ok
+}
+
+// summarySubLogs discard DEBUG and INFO sub-logs and return the number discarded.
+// Custom label like DATABASE or DURATION is kept, it may hold a failed or slow query.
+func summarySubLogs(subLogs []SubLog) (kept []SubLog, discarded int) {
+	for _, subLog := range subLogs {
+		if level, err := ParseLevel(subLog.Level); err == nil && level < LevelWarning {
+			discarded++
+			continue
+		}
+		kept = append(kept, subLog)
+	}
+	return kept, discarded
+}
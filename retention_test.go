@@ -0,0 +1,70 @@
+package log
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSubLogRetentionSummary(t *testing.T) {
+	logger, recorder := newRecordingLogger(t, Config{
+		SubLogRetention: &SubLogRetentionConfig{SlowThreshold: time.Hour},
+	})
+
+	req := logger.NewRequest()
+	req.StatusCode = 200
+	req.Debug("debug")
+	req.Info("info")
+	req.Warn("warn")
+	req.SubLog("[DATABASE] repository/user.go:20", "SLOW SQL >= 200ms SELECT 1")
+	req.Save()
+
+	requests := recorder.requests(t, logger)
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 REQUEST entry, got %d", len(requests))
+	}
+	data := requests[0]
+	if data.SubLogDiscarded != 2 {
+		t.Errorf("expected DEBUG and INFO discarded, got %d", data.SubLogDiscarded)
+	}
+	if len(data.SubLogs) != 2 || data.SubLogs[0].Level != "WARN" || data.SubLogs[1].Level != "DATABASE" {
+		t.Fatalf("expected WARN and custom label sub-logs kept, got %+v", data.SubLogs)
+	}
+}
+
+func TestSubLogRetentionKeepAll(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  SubLogRetentionConfig
+		prepare func(req *request)
+	}{
+		{"error sub-log", SubLogRetentionConfig{}, func(req *request) { req.Error("failed") }},
+		{"custom label error", SubLogRetentionConfig{}, func(req *request) {
+			req.SubLogError("[DATABASE] repository/user.go:20", "duplicate key")
+		}},
+		{"status code", SubLogRetentionConfig{MinStatus: 400}, func(req *request) { req.StatusCode = 404 }},
+		{"slow request", SubLogRetentionConfig{SlowThreshold: time.Millisecond}, func(req *request) {
+			time.Sleep(2 * time.Millisecond)
+		}},
+		{"slow route", SubLogRetentionConfig{SlowThreshold: time.Hour, RouteThreshold: map[string]time.Duration{"GET /reports": time.Millisecond}}, func(req *request) {
+			req.Method, req.Route = "GET", "/reports"
+			time.Sleep(2 * time.Millisecond)
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := test.config
+			logger, recorder := newRecordingLogger(t, Config{SubLogRetention: &config})
+
+			req := logger.NewRequest()
+			req.StatusCode = 200
+			req.Debug("debug")
+			test.prepare(req)
+			req.Save()
+
+			requests := recorder.requests(t, logger)
+			if len(requests) != 1 || requests[0].SubLogDiscarded != 0 || requests[0].SubLogs[0].Message != "debug" {
+				t.Fatalf("expected every sub-log kept, got %+v", requests)
+			}
+		})
+	}
+}
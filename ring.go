@@ -0,0 +1,321 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const ringSubscriberBuffer = 256 // Entries queued per live stream before new entries are dropped
+
+type (
+	// RingBufferConfig set how many entries are kept in memory
+	RingBufferConfig struct {
+		Size             int // Last N entries kept. Default 1000
+		RequestsPerRoute int // When set, REQUEST entries with a Route are kept separately, last N per route
+		MaxRoutes        int // Routes kept by RequestsPerRoute, the least recently written route is removed first. Default 100
+	}
+
+	// RingBuffer keep the last written entries in memory, use it with Config.RingBuffer
+	// and expose them with Handler when file log is not reachable.
+	RingBuffer struct {
+		config      RingBufferConfig
+		mu          sync.RWMutex
+		entries     []ringEntry // Circular buffer of Size entries
+		next        int         // Index of the next write in entries
+		routes      map[string][]ringEntry
+		seq         uint64
+		subscribers map[chan ringEntry]RingFilter
+	}
+
+	ringEntry struct {
+		id    uint64
+		entry Entry
+	}
+
+	// RingFilter select entries of the ring buffer, zero value match every entry
+	RingFilter struct {
+		Levels     []slog.Level // Match one of the levels
+		TraceID    string
+		StatusCode int    // Match REQUEST and TRACE entries with the status code
+		Route      string // Match REQUEST entries with the route
+		Limit      int    // Return the newest N entries
+	}
+)
+
+// NewRingBuffer create in-memory sink for Config.RingBuffer
+func NewRingBuffer(config RingBufferConfig) *RingBuffer {
+	if config.Size <= 0 {
+		config.Size = 1000
+	}
+	if config.MaxRoutes <= 0 {
+		config.MaxRoutes = 100
+	}
+	return &RingBuffer{
+		config:      config,
+		entries:     make([]ringEntry, 0, config.Size),
+		routes:      make(map[string][]ringEntry),
+		subscribers: make(map[chan ringEntry]RingFilter),
+	}
+}
+
+// add store a copy of the entry and send it to live streams
+func (b *RingBuffer) add(entry *Entry) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.seq++
+	item := ringEntry{id: b.seq, entry: *entry}
+
+	// Entry without Route use the main ring, the url path like /users/1 would create a key per url
+	if entry.Request != nil && entry.Request.Route != "" && b.config.RequestsPerRoute > 0 {
+		route := entry.Request.Route
+		if _, found := b.routes[route]; !found && len(b.routes) >= b.config.MaxRoutes {
+			b.evictRoute()
+		}
+		entries := append(b.routes[route], item)
+		if len(entries) > b.config.RequestsPerRoute {
+			entries = append(entries[:0:0], entries[len(entries)-b.config.RequestsPerRoute:]...)
+		}
+		b.routes[route] = entries
+	} else if len(b.entries) < b.config.Size {
+		b.entries = append(b.entries, item)
+	} else {
+		b.entries[b.next] = item
+		b.next = (b.next + 1) % b.config.Size
+	}
+
+	for subscriber, filter := range b.subscribers {
+		if !filter.match(&item.entry) {
+			continue
+		}
+		select {
+		case subscriber <- item:
+		default: // Slow reader, drop instead of blocking the logger
+		}
+	}
+}
+
+// evictRoute remove the route with the oldest last entry, caller must hold mu
+func (b *RingBuffer) evictRoute() {
+	var (
+		oldest   string
+		oldestID uint64
+	)
+	for route, entries := range b.routes {
+		if lastID := entries[len(entries)-1].id; oldestID == 0 || lastID < oldestID {
+			oldest, oldestID = route, lastID
+		}
+	}
+	delete(b.routes, oldest)
+}
+
+// Entries return stored entries matching the filter, oldest first
+func (b *RingBuffer) Entries(filter RingFilter) []Entry {
+	b.mu.RLock()
+	var matched []ringEntry
+	for i := range b.entries {
+		if filter.match(&b.entries[i].entry) {
+			matched = append(matched, b.entries[i])
+		}
+	}
+	for _, entries := range b.routes {
+		for i := range entries {
+			if filter.match(&entries[i].entry) {
+				matched = append(matched, entries[i])
+			}
+		}
+	}
+	b.mu.RUnlock()
+
+	sort.Slice(matched, func(i, j int) bool { return matched[i].id < matched[j].id })
+	if filter.Limit > 0 && len(matched) > filter.Limit {
+		matched = matched[len(matched)-filter.Limit:]
+	}
+
+	result := make([]Entry, len(matched))
+	for i := range matched {
+		result[i] = matched[i].entry
+	}
+	return result
+}
+
+// subscribe return a channel receiving new entries matching the filter until unsubscribe is called
+func (b *RingBuffer) subscribe(filter RingFilter) (entries <-chan ringEntry, unsubscribe func()) {
+	subscriber := make(chan ringEntry, ringSubscriberBuffer)
+
+	b.mu.Lock()
+	b.subscribers[subscriber] = filter
+	b.mu.Unlock()
+
+	return subscriber, func() {
+		b.mu.Lock()
+		delete(b.subscribers, subscriber)
+		b.mu.Unlock()
+	}
+}
+
+// Handler return debug http handler listing the stored entries as JSON array.
+//
+//	GET ?level=error,request&traceID=abc&status=500&route=/users/:id&limit=50
+//
+// With ?stream=true or header "Accept: text/event-stream" new matching entries are streamed as Server-Sent Events.
+func (b *RingBuffer) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET")
+			writeJSONResponse(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		filter, err := parseRingFilter(r)
+		if err != nil {
+			writeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+
+		if r.URL.Query().Get("stream") == "true" || strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+			b.stream(w, r.Context(), filter)
+			return
+		}
+
+		var body bytes.Buffer
+		body.WriteByte('[')
+		for i, entry := range b.Entries(filter) {
+			if i > 0 {
+				body.WriteByte(',')
+			}
+			body.Write(bytes.TrimSuffix(encodeEntryJSON(&entry), []byte("\n")))
+		}
+		body.WriteString("]\n")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body.Bytes())
+	})
+}
+
+// stream write new entries as Server-Sent Events until the client disconnect
+func (b *RingBuffer) stream(w http.ResponseWriter, ctx context.Context, filter RingFilter) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSONResponse(w, http.StatusInternalServerError, map[string]string{"error": "streaming is not supported"})
+		return
+	}
+
+	entries, unsubscribe := b.subscribe(filter)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case item := <-entries:
+			fmt.Fprintf(w, "id: %d\ndata: %s\n\n", item.id, bytes.TrimSuffix(encodeEntryJSON(&item.entry), []byte("\n")))
+			flusher.Flush()
+		}
+	}
+}
+
+func parseRingFilter(r *http.Request) (RingFilter, error) {
+	query := r.URL.Query()
+	filter := RingFilter{
+		TraceID: query.Get("traceID"),
+		Route:   query.Get("route"),
+	}
+
+	if levels := query.Get("level"); levels != "" {
+		for _, name := range strings.Split(levels, ",") {
+			level, err := ParseLevel(name)
+			if err != nil {
+				return RingFilter{}, err
+			}
+			filter.Levels = append(filter.Levels, level)
+		}
+	}
+
+	if status := query.Get("status"); status != "" {
+		statusCode, err := strconv.Atoi(status)
+		if err != nil {
+			return RingFilter{}, fmt.Errorf("invalid status %q", status)
+		}
+		filter.StatusCode = statusCode
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value < 0 {
+			return RingFilter{}, fmt.Errorf("invalid limit %q", limit)
+		}
+		filter.Limit = value
+	}
+	return filter, nil
+}
+
+func (f RingFilter) match(entry *Entry) bool {
+	if len(f.Levels) > 0 {
+		found := false
+		for _, level := range f.Levels {
+			if level == entry.Level {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if f.TraceID != "" && f.TraceID != entry.TraceID {
+		return false
+	}
+
+	if f.StatusCode != 0 {
+		switch {
+		case entry.Request != nil:
+			if entry.Request.StatusCode != f.StatusCode {
+				return false
+			}
+		case entry.Trace != nil:
+			if entry.Trace.StatusCode != f.StatusCode {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+
+	if f.Route != "" && (entry.Request == nil || entryRoute(entry) != f.Route) {
+		return false
+	}
+	return true
+}
+
+// entryRoute return the route pattern of a REQUEST entry, or the url path when route is not set
+func entryRoute(entry *Entry) string {
+	if entry.Request.Route != "" {
+		return entry.Request.Route
+	}
+	return urlPath(entry.Request.URL)
+}
+
+// encodeEntryJSON encode entry the same way as the JSON output format
+func encodeEntryJSON(entry *Entry) []byte {
+	var buf bytes.Buffer
+	handler := slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: LevelDebug, ReplaceAttr: replaceAttr})
+
+	record := slog.NewRecord(entry.Time, entry.Level, "", 0)
+	record.AddAttrs(entry.attrs()...)
+	handler.Handle(context.Background(), record)
+	return buf.Bytes()
+}
@@ -0,0 +1,103 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func requestEntry(route, url string, statusCode int) *Entry {
+	return &Entry{Level: LevelRequest, Request: &RequestData{Route: route, URL: url, StatusCode: statusCode}}
+}
+
+func TestRingBufferSize(t *testing.T) {
+	ring := NewRingBuffer(RingBufferConfig{Size: 3})
+	for i := 0; i < 5; i++ {
+		ring.add(&Entry{Level: LevelInfo, Message: fmt.Sprint(i)})
+	}
+
+	entries := ring.Entries(RingFilter{})
+	if len(entries) != 3 || entries[0].Message != "2" || entries[2].Message != "4" {
+		t.Fatalf("expected the last 3 entries oldest first, got %v", entries)
+	}
+}
+
+func TestRingBufferRequestsPerRoute(t *testing.T) {
+	ring := NewRingBuffer(RingBufferConfig{Size: 2, RequestsPerRoute: 2})
+	for i := 0; i < 3; i++ {
+		ring.add(requestEntry("/users/:id", fmt.Sprintf("/users/%d", i), 200+i))
+		ring.add(&Entry{Level: LevelInfo, Message: fmt.Sprint(i)})
+	}
+
+	requests := ring.Entries(RingFilter{Route: "/users/:id"})
+	if len(requests) != 2 || requests[0].Request.StatusCode != 201 || requests[1].Request.StatusCode != 202 {
+		t.Fatalf("expected the last 2 requests of the route, got %v", requests)
+	}
+	if entries := ring.Entries(RingFilter{Levels: []slog.Level{LevelInfo}}); len(entries) != 2 {
+		t.Fatalf("expected requests not to use the main ring, got %d info entries", len(entries))
+	}
+}
+
+func TestRingBufferRequestWithoutRouteUseMainRing(t *testing.T) {
+	ring := NewRingBuffer(RingBufferConfig{Size: 10, RequestsPerRoute: 5})
+	for i := 0; i < 1000; i++ {
+		ring.add(requestEntry("", fmt.Sprintf("/users/%d", i), 200))
+	}
+
+	if len(ring.routes) != 0 {
+		t.Fatalf("expected no route key for requests without Route, got %d", len(ring.routes))
+	}
+	if entries := ring.Entries(RingFilter{}); len(entries) != 10 {
+		t.Fatalf("expected requests bounded by Size, got %d", len(entries))
+	}
+	if entries := ring.Entries(RingFilter{Route: "/users/999"}); len(entries) != 1 {
+		t.Fatalf("expected route filter to match the url path, got %d", len(entries))
+	}
+}
+
+func TestRingBufferMaxRoutes(t *testing.T) {
+	ring := NewRingBuffer(RingBufferConfig{RequestsPerRoute: 1, MaxRoutes: 2})
+	ring.add(requestEntry("/a", "/a", 200))
+	ring.add(requestEntry("/b", "/b", 200))
+	ring.add(requestEntry("/a", "/a", 201)) // "/b" is now the least recently written
+	ring.add(requestEntry("/c", "/c", 200))
+
+	if len(ring.routes) != 2 {
+		t.Fatalf("expected 2 routes, got %d", len(ring.routes))
+	}
+	if _, found := ring.routes["/b"]; found {
+		t.Fatal("expected least recently written route /b to be removed")
+	}
+	if entries := ring.Entries(RingFilter{Route: "/a"}); len(entries) != 1 || entries[0].Request.StatusCode != 201 {
+		t.Fatalf("expected last request of /a, got %v", entries)
+	}
+}
+
+func TestRingBufferHandler(t *testing.T) {
+	ring := NewRingBuffer(RingBufferConfig{})
+	ring.add(&Entry{Level: LevelError, Message: "failed", TraceID: "abc"})
+	ring.add(&Entry{Level: LevelInfo, Message: "ok"})
+
+	recorder := httptest.NewRecorder()
+	ring.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/logs?level=error&traceID=abc", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", recorder.Code)
+	}
+
+	var body []map[string]any
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("expected JSON array, %v: %s", err, recorder.Body.String())
+	}
+	if len(body) != 1 || body[0]["msg"] != "failed" || body[0]["level"] != "ERROR" {
+		t.Fatalf("expected the error entry only, got %v", body)
+	}
+
+	recorder = httptest.NewRecorder()
+	ring.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/logs?level=unknown", nil))
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 for unknown level, got %d", recorder.Code)
+	}
+}
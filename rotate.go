@@ -0,0 +1,397 @@
+package log
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/lestrrat-go/strftime"
+)
+
+// Compression is the algorithm used for compressing rotated log file
+type Compression string
+
+const (
+	CompressNone Compression = ""
+	CompressGzip Compression = "gzip"
+	CompressZstd Compression = "zstd"
+
+	megabyte = 1024 * 1024
+
+	rotatorCheckInterval = time.Second // How often the rotator check the active file still exist
+)
+
+var strftimeVerb = regexp.MustCompile(`%.`)
+
+type (
+	// rotatorConfig is the file rotation setting taken from Config
+	rotatorConfig struct {
+		pattern      string        // Full path with strftime verb, example /app/log/server_log.%Y-%b-%d.log
+		linkName     string        // Symlink always pointing to the active file. Empty disable the link
+		rotationTime time.Duration // Create new file every period. 0 disable time rotation
+		maxAge       time.Duration // Delete rotated file older than maxAge. 0 keep forever
+		maxSize      int64         // Rotate when the active file reach this size in bytes. 0 disable size rotation
+		maxBackups   int           // Maximum rotated file kept. 0 keep all
+		maxTotalSize int64         // Maximum size in bytes of all log files. 0 unlimited
+		compress     Compression   // Compress rotated file
+		checkEvery   time.Duration // Check the active file still exist at most once per period. Default 1 second
+		dirMode      os.FileMode
+		fileMode     os.FileMode
+	}
+
+	// rotator is an io.Writer writing into a file which is rotated by time and size
+	rotator struct {
+		config    rotatorConfig
+		format    *strftime.Strftime
+		glob      string // Pattern for finding every file created by this rotator
+		mu        sync.Mutex
+		file      *os.File
+		fileInfo  os.FileInfo // Info of the active file when opened, compared with the path by reopenIfMissing
+		filename  string      // Active file name
+		period    time.Time   // Start of the time period of the active file
+		size      int64
+		checkedAt time.Time // Last time the active file was compared with the path
+		closed    bool
+		cleanup   sync.WaitGroup // Pending compression and cleanup
+	}
+)
+
+func newRotator(config rotatorConfig) (*rotator, error) {
+	format, err := strftime.New(config.pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log file pattern %q, %w", config.pattern, err)
+	}
+	if config.dirMode == 0 {
+		config.dirMode = defaultDirMode
+	}
+	if config.fileMode == 0 {
+		config.fileMode = defaultFileMode
+	}
+	if config.checkEvery <= 0 {
+		config.checkEvery = rotatorCheckInterval
+	}
+
+	switch config.compress {
+	case CompressNone, CompressGzip, CompressZstd:
+	default:
+		return nil, fmt.Errorf("unknown compression %q, use gzip or zstd", config.compress)
+	}
+
+	r := &rotator{
+		config: config,
+		format: format,
+		glob:   strftimeVerb.ReplaceAllString(config.pattern, "*"),
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.open(time.Now()); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rotator) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return 0, ErrClosed
+	}
+
+	now := time.Now()
+	switch {
+	case r.file == nil:
+		if err := r.open(now); err != nil {
+			return 0, err
+		}
+	case r.config.rotationTime > 0 && !r.periodStart(now).Equal(r.period):
+		if err := r.rotate(now, false); err != nil {
+			return 0, err
+		}
+	case r.config.maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.config.maxSize:
+		if err := r.rotate(now, true); err != nil {
+			return 0, err
+		}
+	case now.Sub(r.checkedAt) >= r.config.checkEvery:
+		if err := r.reopenIfMissing(now); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Close close the active file and wait for pending compression
+func (r *rotator) Close() error {
+	r.mu.Lock()
+	r.closed = true
+	var err error
+	if r.file != nil {
+		err = r.file.Close()
+		r.file = nil
+	}
+	r.mu.Unlock()
+
+	r.cleanup.Wait()
+	return err
+}
+
+func (r *rotator) periodStart(now time.Time) time.Time {
+	if r.config.rotationTime <= 0 {
+		return time.Time{}
+	}
+	// Truncate on local wall clock, so daily rotation happen at local midnight
+	_, offset := now.Zone()
+	shift := time.Duration(offset) * time.Second
+	return now.Add(shift).Truncate(r.config.rotationTime).Add(-shift)
+}
+
+// open create or append the file of the current time period, caller must hold mu
+func (r *rotator) open(now time.Time) error {
+	r.period = r.periodStart(now)
+	periodTime := r.period
+	if periodTime.IsZero() {
+		periodTime = now
+	}
+	filename := r.format.FormatString(periodTime)
+
+	if err := os.MkdirAll(filepath.Dir(filename), r.config.dirMode); err != nil {
+		return fmt.Errorf("failed create log directory, %w", err)
+	}
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, r.config.fileMode)
+	if err != nil {
+		return fmt.Errorf("failed open log file, %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed read log file info, %w", err)
+	}
+
+	r.file, r.fileInfo, r.filename, r.size, r.checkedAt = file, info, filename, info.Size(), now
+	r.updateLink()
+	return nil
+}
+
+// rotate close the active file and open a new one. When bySize is true the active file is renamed
+// to a numbered backup because the new file use the same time period name. Caller must hold mu.
+func (r *rotator) rotate(now time.Time, bySize bool) error {
+	previous := r.filename
+	r.file.Close()
+	r.file = nil
+
+	if bySize {
+		backup := r.backupName(previous)
+		if err := os.Rename(previous, backup); err == nil {
+			previous = backup
+		}
+	}
+
+	if err := r.open(now); err != nil {
+		return err
+	}
+
+	if previous != r.filename {
+		r.cleanup.Add(1)
+		go func() {
+			defer r.cleanup.Done()
+			r.compressAndClean(previous)
+		}()
+	}
+	return nil
+}
+
+// backupName return the name numbered after the highest existing backup, example server_log.2021-Oct-22.4.log.
+// Number is never reused after old backups are deleted, so a higher number is always a newer file.
+func (r *rotator) backupName(filename string) string {
+	ext := filepath.Ext(filename)
+	base := strings.TrimSuffix(filename, ext)
+
+	highest := 0
+	matches, _ := filepath.Glob(base + ".*" + ext + "*")
+	for _, match := range matches {
+		number, _, _ := strings.Cut(strings.TrimPrefix(match, base+"."), ".")
+		if value, err := strconv.Atoi(number); err == nil && value > highest {
+			highest = value
+		}
+	}
+
+	for i := highest + 1; ; i++ {
+		name := base + "." + strconv.Itoa(i) + ext
+		if !fileExists(name) && !fileExists(name+compressionExt(r.config.compress)) {
+			return name
+		}
+	}
+}
+
+// reopenIfMissing reopen the active file when it was deleted or moved, example the log directory removed.
+// Lines written between two checks go to the old file. Caller must hold mu.
+func (r *rotator) reopenIfMissing(now time.Time) error {
+	r.checkedAt = now
+	onDisk, err := os.Stat(r.filename)
+	if err == nil && os.SameFile(r.fileInfo, onDisk) {
+		return nil
+	}
+
+	r.file.Close()
+	r.file = nil
+	return r.open(now)
+}
+
+// updateLink point the symlink to the active file, replacing it atomically
+func (r *rotator) updateLink() {
+	if r.config.linkName == "" {
+		return
+	}
+
+	target := r.filename
+	if filepath.Dir(target) == filepath.Dir(r.config.linkName) {
+		target = filepath.Base(target)
+	}
+
+	tempLink := r.config.linkName + ".tmp"
+	os.Remove(tempLink)
+	if err := os.Symlink(target, tempLink); err != nil {
+		return
+	}
+	if err := os.Rename(tempLink, r.config.linkName); err != nil {
+		os.Remove(tempLink)
+	}
+}
+
+// compressAndClean compress the rotated file and delete old files over the limit
+func (r *rotator) compressAndClean(rotated string) {
+	if r.config.compress != CompressNone {
+		compressFile(rotated, r.config.compress, r.config.fileMode)
+	}
+	r.deleteOldFiles()
+}
+
+// deleteOldFiles apply maxAge, maxBackups and maxTotalSize to rotated files, newest file is kept first
+func (r *rotator) deleteOldFiles() {
+	if r.config.maxAge <= 0 && r.config.maxBackups <= 0 && r.config.maxTotalSize <= 0 {
+		return
+	}
+
+	r.mu.Lock()
+	active := r.filename
+	activeSize := r.size
+	r.mu.Unlock()
+
+	// Size backups add a number before the extension, example server_log.2021-Oct-22.1.log
+	ext := filepath.Ext(r.glob)
+	unique := make(map[string]bool)
+	var matches []string
+	for _, glob := range []string{r.glob, strings.TrimSuffix(r.glob, ext) + ".*" + ext} {
+		for _, suffix := range []string{"", ".gz", ".zst"} {
+			found, _ := filepath.Glob(glob + suffix)
+			for _, path := range found {
+				if !unique[path] {
+					unique[path] = true
+					matches = append(matches, path)
+				}
+			}
+		}
+	}
+
+	type logFile struct {
+		path    string
+		modTime time.Time
+		size    int64
+	}
+
+	var files []logFile
+	for _, path := range matches {
+		if path == active || path == r.config.linkName {
+			continue
+		}
+		info, err := os.Lstat(path)
+		if err != nil || !info.Mode().IsRegular() || strings.HasSuffix(path, ".tmp") {
+			continue
+		}
+		files = append(files, logFile{path: path, modTime: info.ModTime(), size: info.Size()})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.After(files[j].modTime) })
+
+	totalSize := activeSize
+	cutoff := time.Now().Add(-r.config.maxAge)
+	for i, file := range files {
+		totalSize += file.size
+		switch {
+		case r.config.maxAge > 0 && file.modTime.Before(cutoff),
+			r.config.maxBackups > 0 && i >= r.config.maxBackups,
+			r.config.maxTotalSize > 0 && totalSize > r.config.maxTotalSize:
+			os.Remove(file.path)
+			totalSize -= file.size
+		}
+	}
+}
+
+// compressFile write filename into filename.gz or filename.zst with the same permission and remove the original
+func compressFile(filename string, compression Compression, fileMode os.FileMode) error {
+	source, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	target := filename + compressionExt(compression)
+	file, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, fileMode)
+	if err != nil {
+		return err
+	}
+
+	var writer io.WriteCloser
+	switch compression {
+	case CompressZstd:
+		writer, err = zstd.NewWriter(file)
+		if err != nil {
+			file.Close()
+			os.Remove(target)
+			return err
+		}
+	default:
+		writer = gzip.NewWriter(file)
+	}
+
+	if _, err = io.Copy(writer, source); err == nil {
+		err = writer.Close()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(target)
+		return err
+	}
+	return os.Remove(filename)
+}
+
+func compressionExt(compression Compression) string {
+	switch compression {
+	case CompressGzip:
+		return ".gz"
+	case CompressZstd:
+		return ".zst"
+	default:
+		return ""
+	}
+}
+
+func fileExists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
@@ -0,0 +1,268 @@
+package log
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func newTestRotator(t *testing.T, config rotatorConfig) *rotator {
+	t.Helper()
+
+	r, err := newRotator(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { r.Close() })
+	return r
+}
+
+func writeLine(t *testing.T, w io.Writer, line string) {
+	t.Helper()
+
+	if _, err := w.Write([]byte(line + "\n")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// listDir return the file names in dir, sorted
+func listDir(t *testing.T, dir string) []string {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestRotatorSizeRotation(t *testing.T) {
+	dir := t.TempDir()
+	r := newTestRotator(t, rotatorConfig{
+		pattern:  filepath.Join(dir, "app.%Y%m%d.log"),
+		linkName: filepath.Join(dir, "app.log"),
+		maxSize:  10,
+	})
+
+	writeLine(t, r, "first")
+	writeLine(t, r, "second")
+	writeLine(t, r, "third")
+	r.cleanup.Wait()
+
+	active := r.filename
+	backups, _ := filepath.Glob(strings.TrimSuffix(active, ".log") + ".*.log")
+	sort.Strings(backups)
+	if len(backups) != 2 {
+		t.Fatalf("expected 2 backups, got %v", listDir(t, dir))
+	}
+	for i, expected := range []string{"first\n", "second\n"} {
+		if content := readFile(t, backups[i]); content != expected {
+			t.Errorf("backup %s: expected %q, got %q", backups[i], expected, content)
+		}
+	}
+	if content := readFile(t, filepath.Join(dir, "app.log")); content != "third\n" {
+		t.Errorf("expected link to the active file with the last line, got %q", content)
+	}
+}
+
+func TestRotatorTimeRotation(t *testing.T) {
+	dir := t.TempDir()
+	r := newTestRotator(t, rotatorConfig{
+		pattern:      filepath.Join(dir, "app.%Y%m%d%H%M%S.log"),
+		rotationTime: time.Second,
+	})
+
+	writeLine(t, r, "first")
+	first := r.filename
+	time.Sleep(time.Until(r.period.Add(time.Second)))
+	writeLine(t, r, "second")
+
+	if r.filename == first {
+		t.Fatalf("expected new file after the period, still writing %s", first)
+	}
+	if content := readFile(t, first); content != "first\n" {
+		t.Errorf("expected first period file with first line, got %q", content)
+	}
+	if content := readFile(t, r.filename); content != "second\n" {
+		t.Errorf("expected second period file with second line, got %q", content)
+	}
+}
+
+func TestRotatorCompression(t *testing.T) {
+	for _, compression := range []Compression{CompressGzip, CompressZstd} {
+		t.Run(string(compression), func(t *testing.T) {
+			dir := t.TempDir()
+			r := newTestRotator(t, rotatorConfig{
+				pattern:  filepath.Join(dir, "app.log"),
+				maxSize:  10,
+				compress: compression,
+				fileMode: 0o640,
+			})
+
+			writeLine(t, r, "compressed")
+			writeLine(t, r, "active")
+			r.cleanup.Wait()
+
+			target := filepath.Join(dir, "app.1.log"+compressionExt(compression))
+			file, err := os.Open(target)
+			if err != nil {
+				t.Fatalf("expected compressed backup, got %v", listDir(t, dir))
+			}
+			defer file.Close()
+
+			info, err := file.Stat()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Mode().Perm() != 0o640 {
+				t.Fatalf("expected compressed backup with FileMode 0640, got %v", info.Mode().Perm())
+			}
+
+			var reader io.Reader
+			if compression == CompressZstd {
+				decoder, err := zstd.NewReader(file)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer decoder.Close()
+				reader = decoder
+			} else {
+				decoder, err := gzip.NewReader(file)
+				if err != nil {
+					t.Fatal(err)
+				}
+				reader = decoder
+			}
+			content, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(content) != "compressed\n" {
+				t.Errorf("expected decompressed backup %q, got %q", "compressed\n", content)
+			}
+			if fileExists(filepath.Join(dir, "app.1.log")) {
+				t.Error("expected uncompressed backup to be removed")
+			}
+		})
+	}
+}
+
+func TestRotatorMaxBackups(t *testing.T) {
+	dir := t.TempDir()
+	r := newTestRotator(t, rotatorConfig{
+		pattern:    filepath.Join(dir, "app.log"),
+		maxSize:    10,
+		maxBackups: 2,
+	})
+
+	for _, line := range []string{"line-1", "line-2", "line-3", "line-4", "line-5"} {
+		writeLine(t, r, line)
+		r.cleanup.Wait() // Keep modification time ordered like a real rotation interval
+	}
+
+	if files := listDir(t, dir); strings.Join(files, ",") != "app.3.log,app.4.log,app.log" {
+		t.Fatalf("expected the 2 newest backups and the active file, got %v", files)
+	}
+	if content := readFile(t, filepath.Join(dir, "app.4.log")); content != "line-4\n" {
+		t.Errorf("expected newest backup with line-4, got %q", content)
+	}
+}
+
+func TestRotatorBackupNumberNotReused(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"app.2.log", "app.3.log.gz"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r := newTestRotator(t, rotatorConfig{pattern: filepath.Join(dir, "app.log"), maxSize: 10})
+	if name := r.backupName(r.filename); name != filepath.Join(dir, "app.4.log") {
+		t.Fatalf("expected backup after the highest number, got %s", name)
+	}
+}
+
+func TestRotatorRecreateDeletedDirectory(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "log")
+	r := newTestRotator(t, rotatorConfig{
+		pattern:    filepath.Join(dir, "app.log"),
+		linkName:   filepath.Join(dir, "current.log"),
+		checkEvery: 20 * time.Millisecond,
+	})
+
+	writeLine(t, r, "before delete")
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(30 * time.Millisecond)
+	writeLine(t, r, "after delete")
+
+	if content := readFile(t, filepath.Join(dir, "app.log")); content != "after delete\n" {
+		t.Fatalf("expected line written after the check in the new file, got %q", content)
+	}
+	if content := readFile(t, filepath.Join(dir, "current.log")); content != "after delete\n" {
+		t.Fatalf("expected link to the new file, got %q", content)
+	}
+}
+
+func TestRotatorCheckFileThrottled(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "log")
+	r := newTestRotator(t, rotatorConfig{pattern: filepath.Join(dir, "app.log"), checkEvery: time.Hour})
+
+	writeLine(t, r, "before delete")
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	writeLine(t, r, "inside check interval")
+
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatalf("expected no stat and reopen before the check interval, got %v", err)
+	}
+}
+
+func TestRotatorReturnReopenError(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "log")
+	r := newTestRotator(t, rotatorConfig{pattern: filepath.Join(dir, "app.log"), checkEvery: time.Millisecond})
+
+	writeLine(t, r, "before delete")
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	// A file in place of the directory make the reopen fail
+	if err := os.WriteFile(dir, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	if _, err := r.Write([]byte("line\n")); err == nil || !strings.Contains(err.Error(), "failed create log directory") {
+		t.Fatalf("expected the reopen error, got %v", err)
+	}
+	if _, err := r.Write([]byte("line\n")); err == nil || !strings.Contains(err.Error(), "failed create log directory") {
+		t.Fatalf("expected the reopen error on the next write, got %v", err)
+	}
+}
+
+func TestRotatorClosed(t *testing.T) {
+	r := newTestRotator(t, rotatorConfig{pattern: filepath.Join(t.TempDir(), "app.log")})
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Write([]byte("line\n")); err != ErrClosed {
+		t.Fatalf("expected ErrClosed after Close, got %v", err)
+	}
+	if err := r.Reopen(); err != ErrClosed {
+		t.Fatalf("expected ErrClosed from Reopen after Close, got %v", err)
+	}
+}
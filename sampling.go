@@ -0,0 +1,150 @@
+package log
+
+import (
+	"hash/fnv"
+	"log/slog"
+	"math"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const samplerCounterSize = 4096 // Number of counter slot for global log sampling
+
+type (
+	// SamplingConfig limit the amount of log written by high traffic code path.
+	// ERROR and above global logs, and failed requests are never sampled.
+	SamplingConfig struct {
+		Initial     int                // Global log: first N entries with the same level and message per Tick are written
+		Thereafter  int                // Global log: after Initial, every Mth entry is written. 0 drop all after Initial
+		Tick        time.Duration      // Global log: counter reset interval. Default 1 second
+		RequestRate float64            // REQUEST entry: fraction to keep between 0 and 1. 0 or 1 keep all
+		RouteRates  map[string]float64 // REQUEST entry: fraction to keep per route, example {"GET /health": 0.01, "/users": 0.5}
+	}
+
+	// sampler decide whether an entry should be written
+	sampler struct {
+		config   SamplingConfig
+		counters [samplerCounterSize]samplerCounter
+	}
+
+	samplerCounter struct {
+		resetAt atomic.Int64
+		count   atomic.Uint64
+	}
+)
+
+func newSampler(config *SamplingConfig) *sampler {
+	if config == nil {
+		return nil
+	}
+
+	s := &sampler{config: *config}
+	if s.config.Tick <= 0 {
+		s.config.Tick = time.Second
+	}
+	return s
+}
+
+// allowGlobal implement first N then every Mth per level and message in each tick
+func (s *sampler) allowGlobal(level slog.Level, msg string) bool {
+	if s == nil || level >= LevelError || s.config.Initial <= 0 {
+		return true
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte{byte(level)})
+	hash.Write([]byte(msg))
+	counter := &s.counters[hash.Sum32()%samplerCounterSize]
+
+	count := counter.inc(time.Now().UnixNano(), s.config.Tick.Nanoseconds())
+	if count <= uint64(s.config.Initial) {
+		return true
+	}
+	if s.config.Thereafter <= 0 {
+		return false
+	}
+	return (count-uint64(s.config.Initial))%uint64(s.config.Thereafter) == 0
+}
+
+func (c *samplerCounter) inc(now, tick int64) uint64 {
+	resetAt := c.resetAt.Load()
+	if now < resetAt {
+		return c.count.Add(1)
+	}
+
+	// Start a new tick, only one goroutine win the reset
+	if c.resetAt.CompareAndSwap(resetAt, now+tick) {
+		c.count.Store(1)
+		return 1
+	}
+	return c.count.Add(1)
+}
+
+// allowRequest keep failed request and sample the rest deterministically by trace id,
+// so every service in the same call chain make the same decision.
+func (s *sampler) allowRequest(m *request, hasError bool) bool {
+	if s == nil || m.StatusCode >= 500 || hasError {
+		return true
+	}
+
+	rate, found := s.routeRate(m)
+	if !found {
+		rate = s.config.RequestRate
+		if rate <= 0 {
+			return true
+		}
+	}
+	if rate >= 1 {
+		return true
+	}
+	if rate <= 0 {
+		return false
+	}
+
+	return traceIDRatio(m.traceID) < rate
+}
+
+// routeRate find the rate by "METHOD route" first, then by route only
+func (s *sampler) routeRate(m *request) (float64, bool) {
+	if len(s.config.RouteRates) == 0 {
+		return 0, false
+	}
+
+	route := m.routeKey()
+	if rate, ok := s.config.RouteRates[m.Method+" "+route]; ok {
+		return rate, true
+	}
+	rate, ok := s.config.RouteRates[route]
+	return rate, ok
+}
+
+// traceIDRatio map trace id into a stable number between 0 and 1
+func traceIDRatio(traceID string) float64 {
+	hash := fnv.New64a()
+	hash.Write([]byte(traceID))
+	return float64(hash.Sum64()) / float64(math.MaxUint64)
+}
+
+// routeKey return the route pattern, or the url path without host and query when route is not set
+func (m *request) routeKey() string {
+	if m.Route != "" {
+		return m.Route
+	}
+	return urlPath(m.URL)
+}
+
+// urlPath remove scheme, host and query from url
+func urlPath(url string) string {
+	route := url
+	if index := strings.Index(route, "://"); index >= 0 {
+		route = route[index+3:] // Remove scheme
+	}
+	if index := strings.IndexAny(route, "?#"); index >= 0 {
+		route = route[:index]
+	}
+	if index := strings.Index(route, "/"); index > 0 {
+		route = route[index:] // Remove host
+	}
+	return route
+}
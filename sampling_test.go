@@ -0,0 +1,146 @@
+package log
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestSamplerAllowGlobal(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   SamplingConfig
+		level    string
+		expected []bool
+	}{
+		{"first 2 then every 3rd", SamplingConfig{Initial: 2, Thereafter: 3, Tick: time.Hour}, "INFO", []bool{true, true, false, false, true, false, false, true}},
+		{"first 2 then drop", SamplingConfig{Initial: 2, Tick: time.Hour}, "INFO", []bool{true, true, false, false}},
+		{"error never sampled", SamplingConfig{Initial: 1, Tick: time.Hour}, "ERROR", []bool{true, true, true}},
+		{"initial 0 keep all", SamplingConfig{Thereafter: 5, Tick: time.Hour}, "INFO", []bool{true, true, true}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			level, err := ParseLevel(test.level)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := newSampler(&test.config)
+
+			var allowed []bool
+			for range test.expected {
+				allowed = append(allowed, s.allowGlobal(level, "same message"))
+			}
+			if fmt.Sprint(allowed) != fmt.Sprint(test.expected) {
+				t.Fatalf("expected %v, got %v", test.expected, allowed)
+			}
+		})
+	}
+}
+
+func TestSamplerCountPerMessage(t *testing.T) {
+	s := newSampler(&SamplingConfig{Initial: 1, Tick: time.Hour})
+
+	if !s.allowGlobal(LevelInfo, "first") || !s.allowGlobal(LevelInfo, "second") || !s.allowGlobal(LevelWarning, "first") {
+		t.Fatal("expected each level and message counted separately")
+	}
+	if s.allowGlobal(LevelInfo, "first") {
+		t.Fatal("expected repeated message sampled")
+	}
+}
+
+func TestSamplerCounterResetPerTick(t *testing.T) {
+	var counter samplerCounter
+	tick := time.Second.Nanoseconds()
+
+	for i, expected := range []uint64{1, 2, 3} {
+		if count := counter.inc(int64(i), tick); count != expected {
+			t.Fatalf("expected count %d inside the tick, got %d", expected, count)
+		}
+	}
+	if count := counter.inc(tick+1, tick); count != 1 {
+		t.Fatalf("expected count reset in the next tick, got %d", count)
+	}
+	if count := counter.inc(tick+2, tick); count != 2 {
+		t.Fatalf("expected count 2 after reset, got %d", count)
+	}
+
+	s := newSampler(&SamplingConfig{Initial: 1, Tick: 20 * time.Millisecond})
+	s.allowGlobal(LevelInfo, "tick")
+	if s.allowGlobal(LevelInfo, "tick") {
+		t.Fatal("expected second message in the same tick sampled")
+	}
+	time.Sleep(30 * time.Millisecond)
+	if !s.allowGlobal(LevelInfo, "tick") {
+		t.Fatal("expected message written again after the tick")
+	}
+}
+
+func TestSamplerAllowRequestSameTraceID(t *testing.T) {
+	config := &SamplingConfig{RequestRate: 0.5}
+	first, second := newSampler(config), newSampler(config)
+
+	var kept, dropped int
+	for i := 0; i < 200; i++ {
+		req := &request{traceID: fmt.Sprintf("trace-%d", i), StatusCode: 200}
+		allowed := first.allowRequest(req, false)
+		for j := 0; j < 3; j++ {
+			if first.allowRequest(req, false) != allowed || second.allowRequest(req, false) != allowed {
+				t.Fatalf("expected trace %s to get the same sampling result", req.traceID)
+			}
+		}
+		if allowed {
+			kept++
+		} else {
+			dropped++
+		}
+	}
+	if kept == 0 || dropped == 0 {
+		t.Fatalf("expected rate 0.5 to keep and drop requests, kept %d dropped %d", kept, dropped)
+	}
+}
+
+func TestSamplerAllowRequest(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   SamplingConfig
+		request  *request
+		hasError bool
+		expected bool
+	}{
+		{"rate 0 keep all", SamplingConfig{}, &request{StatusCode: 200}, false, true},
+		{"route rate 0 drop", SamplingConfig{RouteRates: map[string]float64{"/health": 0}}, &request{Route: "/health", StatusCode: 200}, false, false},
+		{"method route rate first", SamplingConfig{RouteRates: map[string]float64{"GET /users": 0, "/users": 1}}, &request{Method: "GET", URL: "https://api.local/users?page=2", StatusCode: 200}, false, false},
+		{"route rate fallback", SamplingConfig{RequestRate: 0.0001, RouteRates: map[string]float64{"/users": 1}}, &request{Method: "POST", Route: "/users", StatusCode: 200}, false, true},
+		{"server error always kept", SamplingConfig{RouteRates: map[string]float64{"/health": 0}}, &request{Route: "/health", StatusCode: 503}, false, true},
+		{"error sub-log always kept", SamplingConfig{RouteRates: map[string]float64{"/health": 0}}, &request{Route: "/health", StatusCode: 200}, true, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if allowed := newSampler(&test.config).allowRequest(test.request, test.hasError); allowed != test.expected {
+				t.Fatalf("expected allowed %v, got %v", test.expected, allowed)
+			}
+		})
+	}
+}
+
+func TestSamplingKeepFailedRequest(t *testing.T) {
+	logger, recorder := newRecordingLogger(t, Config{Sampling: &SamplingConfig{RouteRates: map[string]float64{"/health": 0}}})
+
+	prepares := []func(req *request){
+		func(req *request) {},
+		func(req *request) { req.StatusCode = 500 },
+		func(req *request) { req.Error("failed") },
+		func(req *request) { req.Fatal("failed") },
+		func(req *request) { req.SubLogError("[DATABASE] repository/user.go:20", "duplicate key") },
+	}
+	for _, prepare := range prepares {
+		req := logger.NewRequest()
+		req.Route, req.StatusCode = "/health", 200
+		prepare(req)
+		req.Save()
+	}
+
+	if requests := recorder.requests(t, logger); len(requests) != len(prepares)-1 {
+		t.Fatalf("expected only the %d failed requests kept, got %d", len(prepares)-1, len(requests))
+	}
+}
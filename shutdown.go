@@ -0,0 +1,73 @@
+package log
+
+import (
+	"context"
+	"errors"
+)
+
+// trackSave mark a request or trace save as in-flight until the returned function is called
+func (l *Logger) trackSave() (done func()) {
+	l.pendingMu.Lock()
+	if l.pending == 0 {
+		l.pendingIdle = make(chan struct{})
+	}
+	l.pending++
+	l.pendingMu.Unlock()
+
+	return func() {
+		l.pendingMu.Lock()
+		defer l.pendingMu.Unlock()
+
+		l.pending--
+		if l.pending == 0 {
+			close(l.pendingIdle)
+		}
+	}
+}
+
+// Pending return the number of request and trace saves which are not written yet
+func (l *Logger) Pending() int {
+	l.pendingMu.Lock()
+	defer l.pendingMu.Unlock()
+	return l.pending
+}
+
+// Shutdown wait for every in-flight request.Save and trace.Save until ctx is done,
+// then flush and close the output. It return the number of saves which were lost
+// because the deadline was reached.
+func (l *Logger) Shutdown(ctx context.Context) (lost int, err error) {
+	lost, err = l.waitPending(ctx)
+	return lost, errors.Join(err, l.Close())
+}
+
+// Wait wait for every in-flight request.Save and trace.Save until ctx is done, the output stay open
+func (l *Logger) Wait(ctx context.Context) error {
+	_, err := l.waitPending(ctx)
+	return err
+}
+
+// waitPending wait until there is no in-flight save or ctx is done
+func (l *Logger) waitPending(ctx context.Context) (lost int, err error) {
+	l.pendingMu.Lock()
+	idle := l.pendingIdle
+	if l.pending == 0 {
+		idle = nil
+	}
+	l.pendingMu.Unlock()
+
+	if idle != nil {
+		select {
+		case <-idle:
+		case <-ctx.Done():
+			lost = l.Pending()
+			err = ctx.Err()
+		}
+	}
+
+	return lost, err
+}
+
+// Shutdown wait for in-flight saves of the default logger and close its output
+func Shutdown(ctx context.Context) (lost int, err error) {
+	return Default().Shutdown(ctx)
+}
@@ -0,0 +1,84 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShutdownWaitPendingSave(t *testing.T) {
+	var buf syncWriter
+	output := &strings.Builder{}
+	buf.w = output
+	logger := newTestLogger(t, &buf, Config{Async: &AsyncConfig{}})
+
+	req := logger.NewRequest()
+	req.WaitGroup.Add(1)
+	go func() {
+		defer req.WaitGroup.Done()
+		time.Sleep(20 * time.Millisecond)
+		req.Info("slow goroutine")
+	}()
+	req.Save()
+	if logger.Pending() != 1 {
+		t.Fatalf("expected 1 pending save, got %d", logger.Pending())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	lost, err := logger.Shutdown(ctx)
+	if lost != 0 || err != nil {
+		t.Fatalf("expected every save written, lost %d err %v", lost, err)
+	}
+
+	buf.mu.Lock()
+	defer buf.mu.Unlock()
+	if !strings.Contains(output.String(), "slow goroutine") {
+		t.Fatalf("expected the REQUEST entry flushed before Shutdown return, got %q", output.String())
+	}
+}
+
+func TestShutdownDeadline(t *testing.T) {
+	logger := newTestLogger(t, &strings.Builder{}, Config{})
+
+	release := make(chan struct{})
+	for i := 0; i < 2; i++ {
+		req := logger.NewRequest()
+		req.WaitGroup.Add(1)
+		go func() {
+			defer req.WaitGroup.Done()
+			<-release
+		}()
+		req.Save()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := logger.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected Wait to stop at the deadline, got %v", err)
+	}
+	lost, err := logger.Shutdown(ctx)
+	if lost != 2 || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected 2 lost saves and the deadline error, got %d %v", lost, err)
+	}
+
+	close(release)
+	if err := logger.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if logger.Pending() != 0 {
+		t.Fatalf("expected no pending save, got %d", logger.Pending())
+	}
+}
+
+func TestWaitWithoutPendingSave(t *testing.T) {
+	logger := newTestLogger(t, &strings.Builder{}, Config{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := logger.Wait(ctx); err != nil {
+		t.Fatalf("expected Wait to return at once without pending save, got %v", err)
+	}
+}
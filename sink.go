@@ -0,0 +1,97 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+)
+
+type (
+	// Sink is an additional output with its own level filter and format.
+	// Config.Level still apply to every sink, a sink only narrow the levels it receive.
+	Sink struct {
+		Writer io.Writer   // Destination of the sink
+		Format Format      // Output format. Default Config.Format
+		Levels LevelFilter // Levels written to this sink. Default nil, every level
+	}
+
+	// LevelFilter report whether a level is written to a sink
+	LevelFilter func(level slog.Level) bool
+
+	// sinkHandler fan out each record to every sink accepting its level
+	sinkHandler struct {
+		sinks []routedHandler
+	}
+
+	routedHandler struct {
+		levels  LevelFilter
+		handler slog.Handler
+	}
+)
+
+// LevelRange accept level between min and max inclusive, example LevelRange(LevelError, LevelFatal)
+// write ERROR and FATAL but not TRACE and REQUEST.
+func LevelRange(min, max slog.Level) LevelFilter {
+	return func(level slog.Level) bool {
+		return level >= min && level <= max
+	}
+}
+
+// MinLevel accept level greater than or equal to min, including TRACE and REQUEST
+func MinLevel(min slog.Level) LevelFilter {
+	return func(level slog.Level) bool {
+		return level >= min
+	}
+}
+
+// OnlyLevels accept the listed levels only, example OnlyLevels(LevelRequest)
+func OnlyLevels(levels ...slog.Level) LevelFilter {
+	return func(level slog.Level) bool {
+		for _, l := range levels {
+			if l == level {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func (f LevelFilter) accept(level slog.Level) bool {
+	return f == nil || f(level)
+}
+
+func (h *sinkHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, sink := range h.sinks {
+		if sink.levels.accept(level) && sink.handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *sinkHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, sink := range h.sinks {
+		if sink.levels.accept(r.Level) && sink.handler.Enabled(ctx, r.Level) {
+			errs = append(errs, sink.handler.Handle(ctx, r.Clone()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (h *sinkHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	sinks := make([]routedHandler, len(h.sinks))
+	for i, sink := range h.sinks {
+		sinks[i] = routedHandler{levels: sink.levels, handler: sink.handler.WithAttrs(attrs)}
+	}
+	return &sinkHandler{sinks: sinks}
+}
+
+func (h *sinkHandler) WithGroup(name string) slog.Handler {
+	sinks := make([]routedHandler, len(h.sinks))
+	for i, sink := range h.sinks {
+		sinks[i] = routedHandler{levels: sink.levels, handler: sink.handler.WithGroup(name)}
+	}
+	return &sinkHandler{sinks: sinks}
+}
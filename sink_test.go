@@ -0,0 +1,136 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestLevelFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   LevelFilter
+		accepted []slog.Level
+		rejected []slog.Level
+	}{
+		{"nil accept every level", nil, []slog.Level{LevelDebug, LevelError, LevelRequest}, nil},
+		{"range", LevelRange(LevelError, LevelFatal), []slog.Level{LevelError, LevelFatal}, []slog.Level{LevelWarning, LevelTrace, LevelRequest}},
+		{"min level", MinLevel(LevelError), []slog.Level{LevelError, LevelFatal, LevelTrace, LevelRequest}, []slog.Level{LevelDebug, LevelWarning}},
+		{"only levels", OnlyLevels(LevelTrace, LevelRequest), []slog.Level{LevelTrace, LevelRequest}, []slog.Level{LevelInfo, LevelError, LevelFatal}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, level := range test.accepted {
+				if !test.filter.accept(level) {
+					t.Errorf("expected %s accepted", levelName(level))
+				}
+			}
+			for _, level := range test.rejected {
+				if test.filter.accept(level) {
+					t.Errorf("expected %s rejected", levelName(level))
+				}
+			}
+		})
+	}
+}
+
+func TestSinkRouting(t *testing.T) {
+	var main, errorSink, requestSink, traceSink bytes.Buffer
+	logger, err := Config{
+		Level:        LevelInfo,
+		LevelSet:     true,
+		CustomWriter: &main,
+		Sinks: []Sink{
+			{Writer: &errorSink, Format: FormatLogfmt, Levels: LevelRange(LevelError, LevelFatal)},
+			{Writer: &requestSink, Levels: OnlyLevels(LevelRequest)},
+			{Writer: &traceSink, Levels: OnlyLevels(LevelTrace)},
+		},
+	}.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger.Debug("debug line")
+	logger.Info("info line")
+	logger.Error("error line")
+	req := logger.NewRequest()
+	trace := NewTrace(http.MethodGet, "https://api.local/users", nil, nil, false)
+	trace.Save(req.SaveToContext(context.Background()), &http.Response{StatusCode: http.StatusOK, Header: http.Header{}})
+	req.Save()
+	if err := logger.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		output   *bytes.Buffer
+		contains []string
+		absent   []string
+	}{
+		{"main output receive every enabled level", &main, []string{"info line", "error line", `"level":"REQUEST"`, `"level":"TRACE"`}, []string{"debug line"}},
+		{"error sink in its own format", &errorSink, []string{"level=ERROR", "error line"}, []string{"info line", "REQUEST", "TRACE"}},
+		{"request sink", &requestSink, []string{`"level":"REQUEST"`}, []string{"info line", "error line", "TRACE"}},
+		{"trace sink", &traceSink, []string{`"level":"TRACE"`, "https://api.local/users"}, []string{"info line", "error line", "REQUEST"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			output := test.output.String()
+			for _, text := range test.contains {
+				if !strings.Contains(output, text) {
+					t.Errorf("expected %q in %q", text, output)
+				}
+			}
+			for _, text := range test.absent {
+				if strings.Contains(output, text) {
+					t.Errorf("expected %q not in %q", text, output)
+				}
+			}
+		})
+	}
+}
+
+func TestSinksWithoutMainOutput(t *testing.T) {
+	var sink bytes.Buffer
+	logger, err := Config{Sinks: []Sink{{Writer: &sink, Levels: MinLevel(LevelWarning)}}}.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := logger.slog.Handler().(*sinkHandler); !ok || len(logger.slog.Handler().(*sinkHandler).sinks) != 1 {
+		t.Fatalf("expected only the sink handler without terminal output, got %#v", logger.slog.Handler())
+	}
+
+	logger.Info("info line")
+	logger.Warn("warn line")
+	if output := sink.String(); strings.Contains(output, "info line") || !strings.Contains(output, "warn line") {
+		t.Fatalf("expected only the warn line in the sink, got %q", output)
+	}
+}
+
+func TestConfigFileSinksOnly(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name     string
+		content  string
+		terminal bool
+	}{
+		{"sinks replace terminal", "sinks:\n  - output: file://" + dir + "/a.log\n", false},
+		{"terminal asked explicitly", "log_to_terminal: true\nsinks:\n  - output: file://" + dir + "/b.log\n", true},
+		{"stdout output", "outputs: [stdout]\nsinks:\n  - output: file://" + dir + "/c.log\n", true},
+		{"without sinks", "level: info\n", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg, err := LoadConfig(writeConfigFile(t, test.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer cfg.closeOutputFiles()
+			if cfg.LogToTerminal != test.terminal {
+				t.Fatalf("expected LogToTerminal %v, got %v", test.terminal, cfg.LogToTerminal)
+			}
+		})
+	}
+}
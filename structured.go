@@ -0,0 +1,213 @@
+package log
+
+import (
+	"log/slog"
+)
+
+const badKey = "!BADKEY"
+
+// Debugw log a message with alternating key and value pairs, example Debugw("payment failed", "orderID", id)
+func (l *Logger) Debugw(msg string, keysAndValues ...any) {
+	l.logWithCaller(LevelDebug, msg, globalSkipLevel, argsToAttrs(keysAndValues)...)
+}
+
+// DebugAttrs log a message with typed attributes, example DebugAttrs("payment failed", slog.String("orderID", id))
+func (l *Logger) DebugAttrs(msg string, attrs ...slog.Attr) {
+	l.logWithCaller(LevelDebug, msg, globalSkipLevel, attrs...)
+}
+
+func (l *Logger) Infow(msg string, keysAndValues ...any) {
+	l.logWithCaller(LevelInfo, msg, globalSkipLevel, argsToAttrs(keysAndValues)...)
+}
+
+func (l *Logger) InfoAttrs(msg string, attrs ...slog.Attr) {
+	l.logWithCaller(LevelInfo, msg, globalSkipLevel, attrs...)
+}
+
+func (l *Logger) Warnw(msg string, keysAndValues ...any) {
+	l.logWithCaller(LevelWarning, msg, globalSkipLevel, argsToAttrs(keysAndValues)...)
+}
+
+func (l *Logger) WarnAttrs(msg string, attrs ...slog.Attr) {
+	l.logWithCaller(LevelWarning, msg, globalSkipLevel, attrs...)
+}
+
+func (l *Logger) Errorw(msg string, keysAndValues ...any) {
+	l.logWithCaller(LevelError, msg, globalSkipLevel, argsToAttrs(keysAndValues)...)
+}
+
+func (l *Logger) ErrorAttrs(msg string, attrs ...slog.Attr) {
+	l.logWithCaller(LevelError, msg, globalSkipLevel, attrs...)
+}
+
+func (l *Logger) Fatalw(msg string, keysAndValues ...any) {
+	l.logWithCaller(LevelFatal, msg, globalSkipLevel, argsToAttrs(keysAndValues)...)
+	l.exit(1)
+}
+
+func (l *Logger) FatalAttrs(msg string, attrs ...slog.Attr) {
+	l.logWithCaller(LevelFatal, msg, globalSkipLevel, attrs...)
+	l.exit(1)
+}
+
+func Debugw(msg string, keysAndValues ...any) {
+	Default().logWithCaller(LevelDebug, msg, globalSkipLevel, argsToAttrs(keysAndValues)...)
+}
+
+func DebugAttrs(msg string, attrs ...slog.Attr) {
+	Default().logWithCaller(LevelDebug, msg, globalSkipLevel, attrs...)
+}
+
+func Infow(msg string, keysAndValues ...any) {
+	Default().logWithCaller(LevelInfo, msg, globalSkipLevel, argsToAttrs(keysAndValues)...)
+}
+
+func InfoAttrs(msg string, attrs ...slog.Attr) {
+	Default().logWithCaller(LevelInfo, msg, globalSkipLevel, attrs...)
+}
+
+func Warnw(msg string, keysAndValues ...any) {
+	Default().logWithCaller(LevelWarning, msg, globalSkipLevel, argsToAttrs(keysAndValues)...)
+}
+
+func WarnAttrs(msg string, attrs ...slog.Attr) {
+	Default().logWithCaller(LevelWarning, msg, globalSkipLevel, attrs...)
+}
+
+func Errorw(msg string, keysAndValues ...any) {
+	Default().logWithCaller(LevelError, msg, globalSkipLevel, argsToAttrs(keysAndValues)...)
+}
+
+func ErrorAttrs(msg string, attrs ...slog.Attr) {
+	Default().logWithCaller(LevelError, msg, globalSkipLevel, attrs...)
+}
+
+func Fatalw(msg string, keysAndValues ...any) {
+	logger := Default()
+	logger.logWithCaller(LevelFatal, msg, globalSkipLevel, argsToAttrs(keysAndValues)...)
+	logger.exit(1)
+}
+
+func FatalAttrs(msg string, attrs ...slog.Attr) {
+	logger := Default()
+	logger.logWithCaller(LevelFatal, msg, globalSkipLevel, attrs...)
+	logger.exit(1)
+}
+
+func (m *request) Debugw(msg string, keysAndValues ...any) {
+	if m.skip(LevelDebug) {
+		return
+	}
+	m.log(LevelDebug, msg, argsToAttrs(keysAndValues)...)
+}
+
+func (m *request) DebugAttrs(msg string, attrs ...slog.Attr) {
+	if m.skip(LevelDebug) {
+		return
+	}
+	m.log(LevelDebug, msg, attrs...)
+}
+
+func (m *request) Infow(msg string, keysAndValues ...any) {
+	if m.skip(LevelInfo) {
+		return
+	}
+	m.log(LevelInfo, msg, argsToAttrs(keysAndValues)...)
+}
+
+func (m *request) InfoAttrs(msg string, attrs ...slog.Attr) {
+	if m.skip(LevelInfo) {
+		return
+	}
+	m.log(LevelInfo, msg, attrs...)
+}
+
+func (m *request) Warnw(msg string, keysAndValues ...any) {
+	if m.skip(LevelWarning) {
+		return
+	}
+	m.log(LevelWarning, msg, argsToAttrs(keysAndValues)...)
+}
+
+func (m *request) WarnAttrs(msg string, attrs ...slog.Attr) {
+	if m.skip(LevelWarning) {
+		return
+	}
+	m.log(LevelWarning, msg, attrs...)
+}
+
+func (m *request) Errorw(msg string, keysAndValues ...any) {
+	if m.skip(LevelError) {
+		return
+	}
+	m.log(LevelError, msg, argsToAttrs(keysAndValues)...)
+}
+
+func (m *request) ErrorAttrs(msg string, attrs ...slog.Attr) {
+	if m.skip(LevelError) {
+		return
+	}
+	m.log(LevelError, msg, attrs...)
+}
+
+func (m *request) Fatalw(msg string, keysAndValues ...any) {
+	if m.skip(LevelFatal) {
+		return
+	}
+	m.log(LevelFatal, msg, argsToAttrs(keysAndValues)...)
+}
+
+func (m *request) FatalAttrs(msg string, attrs ...slog.Attr) {
+	if m.skip(LevelFatal) {
+		return
+	}
+	m.log(LevelFatal, msg, attrs...)
+}
+
+// argsToAttrs convert alternating key and value pairs into attributes, following slog rules.
+// slog.Attr can be mixed in, value without a string key is stored under "!BADKEY".
+func argsToAttrs(args []any) []slog.Attr {
+	var attrs []slog.Attr
+	for len(args) > 0 {
+		switch key := args[0].(type) {
+		case slog.Attr:
+			attrs = append(attrs, key)
+			args = args[1:]
+		case string:
+			if len(args) == 1 {
+				attrs = append(attrs, slog.String(badKey, key))
+				args = args[1:]
+			} else {
+				attrs = append(attrs, slog.Any(key, args[1]))
+				args = args[2:]
+			}
+		default:
+			attrs = append(attrs, slog.Any(badKey, key))
+			args = args[1:]
+		}
+	}
+	return attrs
+}
+
+// attrsToMap convert attributes into a map for sub-log fields, group become a nested map
+func attrsToMap(attrs []slog.Attr) map[string]any {
+	if len(attrs) == 0 {
+		return nil
+	}
+
+	result := make(map[string]any, len(attrs))
+	for _, a := range attrs {
+		value := a.Value.Resolve()
+		switch {
+		case value.Kind() == slog.KindGroup && a.Key == "":
+			for k, v := range attrsToMap(value.Group()) {
+				result[k] = v // Inline group without key
+			}
+		case value.Kind() == slog.KindGroup:
+			result[a.Key] = attrsToMap(value.Group())
+		case a.Key != "":
+			result[a.Key] = value.Any()
+		}
+	}
+	return result
+}
@@ -0,0 +1,99 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"testing"
+)
+
+func TestArgsToAttrs(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []any
+		expected []slog.Attr
+	}{
+		{"pairs", []any{"id", 10, "name", "John"}, []slog.Attr{slog.Int("id", 10), slog.String("name", "John")}},
+		{"attr mixed in", []any{slog.Bool("ok", true), "id", 10}, []slog.Attr{slog.Bool("ok", true), slog.Int("id", 10)}},
+		{"missing value", []any{"id", 10, "dangling"}, []slog.Attr{slog.Int("id", 10), slog.String(badKey, "dangling")}},
+		{"non string key", []any{10, "id", 11}, []slog.Attr{slog.Int(badKey, 10), slog.Int("id", 11)}},
+		{"empty", nil, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attrs := argsToAttrs(test.args)
+			if len(attrs) != len(test.expected) {
+				t.Fatalf("expected %v, got %v", test.expected, attrs)
+			}
+			for i := range attrs {
+				if !attrs[i].Equal(test.expected[i]) {
+					t.Fatalf("expected %v, got %v", test.expected, attrs)
+				}
+			}
+		})
+	}
+}
+
+func TestAttrsToMap(t *testing.T) {
+	fields := attrsToMap([]slog.Attr{
+		slog.Int("id", 10),
+		slog.Group("user", slog.String("name", "John"), slog.Group("address", slog.String("city", "Jakarta"))),
+		slog.Group("", slog.Bool("inline", true)),
+		{},
+	})
+	expected := map[string]any{
+		"id":     int64(10),
+		"user":   map[string]any{"name": "John", "address": map[string]any{"city": "Jakarta"}},
+		"inline": true,
+	}
+	if !reflect.DeepEqual(fields, expected) {
+		t.Fatalf("expected %v, got %v", expected, fields)
+	}
+	if attrsToMap(nil) != nil {
+		t.Fatal("expected nil fields without attributes")
+	}
+}
+
+func TestStructuredGlobalLog(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newTestLogger(t, &buf, Config{})
+
+	logger.Infow("payment failed", "orderID", "A-1", "amount", 1500)
+	logger.ErrorAttrs("payment failed", slog.String("orderID", "A-2"), slog.Group("card", slog.String("type", "visa")))
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %q", buf.String())
+	}
+	var first, second map[string]any
+	json.Unmarshal(lines[0], &first)
+	json.Unmarshal(lines[1], &second)
+	if first["level"] != "INFO" || first["msg"] != "payment failed" || first["orderID"] != "A-1" || first["amount"] != float64(1500) {
+		t.Fatalf("expected key value pairs as top level fields, got %v", first)
+	}
+	if second["level"] != "ERROR" || second["orderID"] != "A-2" || second["card"].(map[string]any)["type"] != "visa" {
+		t.Fatalf("expected typed attributes with group, got %v", second)
+	}
+}
+
+func TestStructuredSubLog(t *testing.T) {
+	logger, recorder := newRecordingLogger(t, Config{})
+
+	req := logger.NewRequest()
+	req.Warnw("slow query", "table", "users", "rows", 2)
+	req.DebugAttrs("cache", slog.Bool("hit", false))
+	req.Save()
+
+	requests := recorder.requests(t, logger)
+	if len(requests) != 1 || len(requests[0].SubLogs) != 2 {
+		t.Fatalf("expected 1 REQUEST entry with 2 sub-logs, got %+v", requests)
+	}
+	subLogs := requests[0].SubLogs
+	if subLogs[0].Level != "WARN" || !reflect.DeepEqual(subLogs[0].Fields, map[string]any{"table": "users", "rows": int64(2)}) {
+		t.Fatalf("expected fields in the WARN sub-log, got %+v", subLogs[0])
+	}
+	if subLogs[1].Level != "DEBUG" || subLogs[1].Fields["hit"] != false {
+		t.Fatalf("expected fields in the DEBUG sub-log, got %+v", subLogs[1])
+	}
+}
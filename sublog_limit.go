@@ -0,0 +1,114 @@
+package log
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+const truncatedSuffix = "...(truncated)"
+
+type (
+	// SubLogLimitConfig bound the sub-logs kept per request. The first Head sub-logs are kept,
+	// then only the last Tail sub-logs. Dropped sub-logs are counted in subLogDropped.
+	SubLogLimitConfig struct {
+		Head           int // First N sub-logs kept. 0 with Tail 0 keep every sub-log up to MaxBytes
+		Tail           int // Last M sub-logs kept after Head is full
+		MaxBytes       int // Maximum total size of kept sub-logs. Default 0, unlimited
+		MaxMessageSize int // Longer message is cut and suffixed with "...(truncated)". Default 0, unlimited
+	}
+
+	// subLogBuffer keep the head and tail of the sub-logs of one request, guarded by request.mu
+	subLogBuffer struct {
+		limit     *SubLogLimitConfig
+		head      []SubLog
+		tail      []sizedSubLog // Last Tail sub-logs, oldest first
+		bytes     int
+		dropped   int
+		truncated bool // Any message was cut or sub-log dropped
+	}
+
+	sizedSubLog struct {
+		subLog SubLog
+		size   int
+	}
+)
+
+// add store the sub-log following the limit
+func (b *subLogBuffer) add(subLog SubLog) {
+	if b.limit == nil {
+		b.head = append(b.head, subLog)
+		return
+	}
+
+	if max := b.limit.MaxMessageSize; max > 0 && len(subLog.Message) > max {
+		subLog.Message = cutUTF8(subLog.Message, max) + truncatedSuffix
+		b.truncated = true
+	}
+
+	size := subLogSize(&subLog)
+	if b.limit.MaxBytes > 0 && size > b.limit.MaxBytes {
+		b.drop()
+		return
+	}
+
+	if b.limit.Head == 0 && b.limit.Tail == 0 || len(b.head) < b.limit.Head {
+		if b.limit.MaxBytes > 0 && b.bytes+size > b.limit.MaxBytes {
+			b.drop()
+			return
+		}
+		b.head = append(b.head, subLog)
+		b.bytes += size
+		return
+	}
+
+	if b.limit.Tail == 0 {
+		b.drop()
+		return
+	}
+
+	// Evict the oldest tail sub-logs until the new one fit
+	for len(b.tail) > 0 && (len(b.tail) >= b.limit.Tail || b.limit.MaxBytes > 0 && b.bytes+size > b.limit.MaxBytes) {
+		b.bytes -= b.tail[0].size
+		b.tail = b.tail[1:]
+		b.drop()
+	}
+	if b.limit.MaxBytes > 0 && b.bytes+size > b.limit.MaxBytes {
+		b.drop() // Head alone use the whole budget
+		return
+	}
+
+	b.tail = append(b.tail, sizedSubLog{subLog: subLog, size: size})
+	b.bytes += size
+}
+
+func (b *subLogBuffer) drop() {
+	b.dropped++
+	b.truncated = true
+}
+
+// list return the kept sub-logs in order, head first then tail
+func (b *subLogBuffer) list() []SubLog {
+	subLogs := make([]SubLog, 0, len(b.head)+len(b.tail))
+	subLogs = append(subLogs, b.head...)
+	for _, item := range b.tail {
+		subLogs = append(subLogs, item.subLog)
+	}
+	return subLogs
+}
+
+// subLogSize estimate the encoded size of a sub-log
+func subLogSize(subLog *SubLog) int {
+	size := len(subLog.Level) + len(subLog.Caller) + len(subLog.Message) + 64 // Time, offset, goroutine and keys
+	for key, value := range subLog.Fields {
+		size += len(key) + len(fmt.Sprint(value)) + 4
+	}
+	return size
+}
+
+// cutUTF8 cut s to at most max bytes without splitting a character
+func cutUTF8(s string, max int) string {
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+	return s[:max]
+}
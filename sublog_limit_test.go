@@ -0,0 +1,87 @@
+package log
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// numbered return the messages "0", "1", ... "n-1", each sub-log is 69 bytes for subLogSize
+func numbered(n int) []string {
+	messages := make([]string, n)
+	for i := range messages {
+		messages[i] = fmt.Sprint(i)
+	}
+	return messages
+}
+
+func TestSubLogBuffer(t *testing.T) {
+	const size = 69 // subLogSize of an INFO sub-log with a one byte message and no caller
+
+	tests := []struct {
+		name      string
+		limit     *SubLogLimitConfig
+		messages  []string
+		expected  []string
+		dropped   int
+		truncated bool
+	}{
+		{"unlimited", nil, numbered(5), numbered(5), 0, false},
+		{"head only", &SubLogLimitConfig{Head: 2}, numbered(5), []string{"0", "1"}, 3, true},
+		{"tail only", &SubLogLimitConfig{Tail: 2}, numbered(5), []string{"3", "4"}, 3, true},
+		{"head and tail", &SubLogLimitConfig{Head: 2, Tail: 2}, numbered(7), []string{"0", "1", "5", "6"}, 3, true},
+		{"tail wrap around many times", &SubLogLimitConfig{Head: 1, Tail: 3}, numbered(10), []string{"0", "7", "8", "9"}, 6, true},
+		{"limit not reached", &SubLogLimitConfig{Head: 2, Tail: 2}, numbered(4), numbered(4), 0, false},
+		{"byte budget without head and tail", &SubLogLimitConfig{MaxBytes: 3 * size}, numbered(5), []string{"0", "1", "2"}, 2, true},
+		{"byte budget evict tail", &SubLogLimitConfig{Head: 1, Tail: 5, MaxBytes: 3 * size}, numbered(6), []string{"0", "4", "5"}, 3, true},
+		{"byte budget used by head", &SubLogLimitConfig{Head: 3, Tail: 2, MaxBytes: 2 * size}, numbered(5), []string{"0", "1"}, 3, true},
+		{"one oversized sub-log", &SubLogLimitConfig{Tail: 3, MaxBytes: 3 * size}, []string{"0", strings.Repeat("x", 3*size), "2"}, []string{"0", "2"}, 1, true},
+		{"message cut", &SubLogLimitConfig{MaxMessageSize: 3}, []string{"abcdef", "abc"}, []string{"abc" + truncatedSuffix, "abc"}, 0, true},
+		{"message cut keep utf-8", &SubLogLimitConfig{MaxMessageSize: 2}, []string{"héllo"}, []string{"h" + truncatedSuffix}, 0, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buffer := subLogBuffer{limit: test.limit}
+			for _, message := range test.messages {
+				buffer.add(SubLog{Level: subLevelInfo, Message: message})
+			}
+
+			var messages []string
+			for _, subLog := range buffer.list() {
+				messages = append(messages, subLog.Message)
+			}
+			if !reflect.DeepEqual(messages, test.expected) {
+				t.Errorf("expected sub-logs %q, got %q", test.expected, messages)
+			}
+			if buffer.dropped != test.dropped {
+				t.Errorf("expected %d dropped, got %d", test.dropped, buffer.dropped)
+			}
+			if buffer.truncated != test.truncated {
+				t.Errorf("expected truncated %v, got %v", test.truncated, buffer.truncated)
+			}
+		})
+	}
+}
+
+func TestSubLogLimitRequestEntry(t *testing.T) {
+	logger, recorder := newRecordingLogger(t, Config{SubLogLimit: &SubLogLimitConfig{Head: 1, Tail: 1}})
+
+	req := logger.NewRequest()
+	for i := 0; i < 5; i++ {
+		req.Infof("line %d", i)
+	}
+	req.Save()
+
+	requests := recorder.requests(t, logger)
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 REQUEST entry, got %d", len(requests))
+	}
+	data := requests[0]
+	if len(data.SubLogs) != 2 || data.SubLogs[0].Message != "line 0" || data.SubLogs[1].Message != "line 4" {
+		t.Fatalf("expected first and last sub-log, got %+v", data.SubLogs)
+	}
+	if data.SubLogDropped != 3 || !data.Truncated {
+		t.Fatalf("expected 3 dropped and truncated, got %d %v", data.SubLogDropped, data.Truncated)
+	}
+}